@@ -1,6 +1,6 @@
 module github.com/itcomusic/excelstruct/_example
 
-go 1.22
+go 1.23
 
 replace github.com/itcomusic/excelstruct => ../
 
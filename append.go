@@ -0,0 +1,168 @@
+package excelstruct
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// appendTitle returns the title read from the existing sheet whose rows are appended after the last data row
+// and the styles of the last data row by column, nil if the sheet has no data rows.
+// The columns of the sheet which are not mapped to the struct are left empty.
+func appendTitle[T any](file *excelize.File, config titleConfig) (*title, []int, error) {
+	if idx, err := file.GetSheetIndex(config.sheetName); err != nil || idx == -1 {
+		return nil, nil, fmt.Errorf("sheet %q not found", config.sheetName)
+	}
+
+	st, err := newTitleFromStruct[T](config, file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := file.Rows(config.sheetName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("rows: %w", err)
+	}
+	defer rows.Close()
+
+	var header []string
+	lastRow := config.rowIndex
+	for row := 1; rows.Next(); row++ {
+		if row < config.rowIndex {
+			continue
+		}
+
+		column, err := rows.Columns()
+		if err != nil {
+			return nil, nil, fmt.Errorf("row %d columns: %w", row, err)
+		}
+
+		if row == config.rowIndex {
+			header = column
+			continue
+		}
+
+		for _, v := range column {
+			if !isEmptyString(strings.TrimSpace(v)) {
+				lastRow = row
+				break
+			}
+		}
+	}
+
+	if err := rows.Error(); err != nil {
+		return nil, nil, fmt.Errorf("rows: %w", err)
+	}
+
+	names := appendTitleNames[T](st, len(header))
+	t := &title{
+		file:   file,
+		name:   make([]titleName, 0, len(header)),
+		idx:    make(map[string]int, len(header)),
+		numFmt: st.numFmt,
+		config: config,
+	}
+
+	for i, v := range header {
+		name, ok := names[strings.TrimSpace(v)]
+		if !ok {
+			continue
+		}
+
+		if idx, ok := t.idx[name]; ok {
+			t.name[idx].Column = append(t.name[idx].Column, i+1)
+			t.name[idx].RowData[len(t.name[idx].Column)-1] = lastRow
+			continue
+		}
+
+		t.name = append(t.name, titleName{
+			Name:    name,
+			Column:  []int{i + 1},
+			Width:   map[int]float64{},
+			RowData: map[int]int{0: lastRow}, // pointer to the last data row
+		})
+		t.idx[name] = len(t.name) - 1
+	}
+
+	for _, n := range st.name {
+		if _, ok := t.idx[n.Name]; !ok {
+			return nil, nil, fmt.Errorf("title %q not found in sheet %q row %d", config.conv(n.Name), config.sheetName, config.rowIndex)
+		}
+	}
+
+	t.config.appendRow = lastRow + 1
+	if lastRow == config.rowIndex {
+		return t, nil, nil
+	}
+
+	styles := make([]int, len(header))
+	for i := range styles {
+		cell, _ := excelize.CoordinatesToCellName(i+1, lastRow)
+		if styles[i], err = file.GetCellStyle(config.sheetName, cell); err != nil {
+			return nil, nil, fmt.Errorf("cell %q style: %w", cell, err)
+		}
+	}
+	return t, styles, nil
+}
+
+// appendTitleNames returns the names of the struct by the text of the title,
+// the groups are expanded up to the number of the columns.
+func appendTitleNames[T any](st *title, columns int) map[string]string {
+	names := make(map[string]string, len(st.name))
+	for _, n := range st.name {
+		names[st.config.conv(n.Name)] = n.Name
+	}
+
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		return names
+	}
+
+	for _, f := range cachedTypeFields(t, typeOpts{structTag: st.config.tag}).list {
+		if f.group == "" {
+			continue
+		}
+
+		gt, ok := groupStruct(f.typ)
+		if !ok {
+			continue
+		}
+
+		for n := 2; n <= columns; n++ {
+			for _, sf := range groupFields(gt, st.config.tag) {
+				name := groupTitle(f.group, n, sf.name)
+				names[st.config.conv(name)] = name
+			}
+		}
+	}
+	return names
+}
+
+// copyRowStyle sets the styles of the last data row to the appended rows,
+// the cell style is applied if the sheet had no data rows.
+func (e *Encoder[T]) copyRowStyle() error {
+	t := e.enc.title
+	if e.appendStyles == nil {
+		return e.applyCellStyle(t.config.appendRow)
+	}
+
+	maxRow := t.maxRowData()
+	if maxRow < t.config.appendRow {
+		return nil
+	}
+
+	for i, style := range e.appendStyles {
+		if style == 0 {
+			continue
+		}
+
+		from, _ := excelize.CoordinatesToCellName(i+1, t.config.appendRow)
+		to, _ := excelize.CoordinatesToCellName(i+1, maxRow)
+		if err := e.SetCellStyle(t.config.sheetName, from, to, style); err != nil {
+			return fmt.Errorf("cell %q style: %w", from, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package excelstruct
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// area is the rectangular range of cells on a sheet.
+type area struct {
+	sheetName string
+	startCol  int
+	startRow  int
+	endCol    int
+	endRow    int
+}
+
+// apply sets the options to read the area where the first row is the title.
+func (a area) apply(o *DecoderOptions) {
+	o.SheetName = a.sheetName
+	o.TitleRowIndex = a.startRow
+	o.DataStartRow = max(o.DataStartRow, a.startRow+1)
+	if o.DataEndRow == 0 || o.DataEndRow > a.endRow {
+		o.DataEndRow = a.endRow
+	}
+	o.FirstColumn = max(o.FirstColumn, a.startCol)
+	if o.LastColumn == 0 || o.LastColumn > a.endCol {
+		o.LastColumn = a.endCol
+	}
+}
+
+// resolveArea sets the options to read the table or the defined name if they are specified.
+func resolveArea(file *excelize.File, o *DecoderOptions) error {
+	var (
+		a   area
+		err error
+	)
+
+	switch {
+	case o.TableName != "":
+		a, err = findTable(file, o.TableName)
+	case o.DefinedName != "":
+		a, err = findDefinedName(file, o.DefinedName)
+	default:
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	a.apply(o)
+	return nil
+}
+
+// findTable returns the area of the table (ListObject) by name.
+func findTable(file *excelize.File, name string) (area, error) {
+	for _, sheetName := range file.GetSheetList() {
+		tables, err := file.GetTables(sheetName)
+		if err != nil {
+			return area{}, fmt.Errorf("sheet %q tables: %w", sheetName, err)
+		}
+
+		for _, t := range tables {
+			if !strings.EqualFold(t.Name, name) {
+				continue
+			}
+
+			a, err := parseRange(t.Range)
+			if err != nil {
+				return area{}, fmt.Errorf("table %q: %w", name, err)
+			}
+			a.sheetName = sheetName
+			return a, nil
+		}
+	}
+	return area{}, fmt.Errorf("table %q not found", name)
+}
+
+// findDefinedName returns the area of the defined name.
+func findDefinedName(file *excelize.File, name string) (area, error) {
+	for _, dn := range file.GetDefinedName() {
+		if !strings.EqualFold(dn.Name, name) {
+			continue
+		}
+
+		ref := strings.TrimPrefix(dn.RefersTo, "=")
+		i := strings.LastIndex(ref, "!")
+		if i == -1 {
+			return area{}, fmt.Errorf("defined name %q refers to %q without sheet", name, dn.RefersTo)
+		}
+
+		a, err := parseRange(ref[i+1:])
+		if err != nil {
+			return area{}, fmt.Errorf("defined name %q: %w", name, err)
+		}
+
+		sheetName := ref[:i]
+		if strings.HasPrefix(sheetName, "'") && strings.HasSuffix(sheetName, "'") {
+			sheetName = strings.ReplaceAll(sheetName[1:len(sheetName)-1], "''", "'")
+		}
+		a.sheetName = sheetName
+		return a, nil
+	}
+	return area{}, fmt.Errorf("defined name %q not found", name)
+}
+
+// parseRange parses the range reference such as "$A$1:$D$10".
+func parseRange(ref string) (area, error) {
+	start, end, ok := strings.Cut(strings.ReplaceAll(ref, "$", ""), ":")
+	if !ok {
+		end = start
+	}
+
+	var (
+		a   area
+		err error
+	)
+	if a.startCol, a.startRow, err = excelize.CellNameToCoordinates(start); err != nil {
+		return area{}, fmt.Errorf("range %q: %w", ref, err)
+	}
+
+	if a.endCol, a.endRow, err = excelize.CellNameToCoordinates(end); err != nil {
+		return area{}, fmt.Errorf("range %q: %w", ref, err)
+	}
+	return a, nil
+}
+
+// resolveSheets returns the names of sheets to read in order.
+func resolveSheets(file *excelize.File, o DecoderOptions) ([]string, error) {
+	if o.TableName != "" || o.DefinedName != "" || (len(o.SheetNames) == 0 && o.SheetPattern == "") {
+		return []string{o.SheetName}, nil
+	}
+
+	list := file.GetSheetList()
+	sheets := make([]string, 0, len(list))
+	for _, name := range o.SheetNames {
+		if !slices.Contains(list, name) {
+			return nil, fmt.Errorf("sheet %q not found", name)
+		}
+		sheets = append(sheets, name)
+	}
+
+	if o.SheetPattern != "" {
+		for _, name := range list {
+			ok, err := path.Match(o.SheetPattern, name)
+			if err != nil {
+				return nil, fmt.Errorf("sheet pattern %q: %w", o.SheetPattern, err)
+			}
+
+			if ok && !slices.Contains(sheets, name) {
+				sheets = append(sheets, name)
+			}
+		}
+	}
+
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("sheet pattern %q does not match any sheet", o.SheetPattern)
+	}
+	return sheets, nil
+}
@@ -0,0 +1,162 @@
+package excelstruct
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// Block is the table of data found on a sheet.
+// The block ends before the next block or the first blank row.
+type Block struct {
+	Name          string
+	SheetName     string
+	TitleRowIndex int
+	EndRow        int // last row of data
+}
+
+// BlockTitle describes the title row of a block.
+type BlockTitle struct {
+	Name   string
+	Title  []string // titles which must be present in the title row
+	Anchor int      // the title row index, the row is not matched by titles
+	typ    reflect.Type
+}
+
+// BlockOf returns the block title which must contain all titles of the struct fields,
+// the titles set by hand are used instead.
+func BlockOf[T any](name string) BlockTitle {
+	return BlockTitle{Name: name, typ: reflect.TypeFor[T]()}
+}
+
+// BlockOptions is the options for scan blocks.
+type BlockOptions struct {
+	SheetName string
+	TitleConv TitleConv
+	StructTag string
+	Block     []BlockTitle
+}
+
+func (o *BlockOptions) initDefault() {
+	if o.SheetName == "" {
+		o.SheetName = DefaultSheetName
+	}
+
+	if o.TitleConv == nil {
+		o.TitleConv = defaultTitleConv
+	}
+
+	if o.StructTag == "" {
+		o.StructTag = defaultTag
+	}
+}
+
+// ScanBlocks scans the sheet for the title rows of blocks and returns the blocks in order of rows.
+func ScanBlocks(r *Read, opts BlockOptions) ([]Block, error) {
+	opts.initDefault()
+
+	// the titles of the struct are not added to the blocks of the caller
+	opts.Block = slices.Clone(opts.Block)
+	for i := range opts.Block {
+		b := &opts.Block[i]
+		if len(b.Title) == 0 && b.typ != nil && b.typ.Kind() == reflect.Struct {
+			for _, f := range cachedTypeFields(b.typ, typeOpts{structTag: opts.StructTag}).list {
+				if f.meta == "" && f.group == "" {
+					b.Title = append(b.Title, f.name)
+				}
+			}
+		}
+
+		if len(b.Title) == 0 && b.Anchor == 0 {
+			return nil, fmt.Errorf("excelstruct: block %q has neither titles nor anchor", b.Name)
+		}
+	}
+
+	rows, err := r.Rows(opts.SheetName)
+	if err != nil {
+		return nil, fmt.Errorf("excelstruct: rows: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		blocks []Block
+		open   bool // the last block has not ended yet
+	)
+
+	for row := 1; rows.Next(); row++ {
+		column, err := rows.Columns()
+		if err != nil {
+			return nil, fmt.Errorf("excelstruct: row %d columns: %w", row, err)
+		}
+
+		if b, ok := opts.matchBlock(row, column); ok {
+			if open {
+				blocks[len(blocks)-1].EndRow = row - 1
+			}
+
+			blocks = append(blocks, Block{
+				Name:          b.Name,
+				SheetName:     opts.SheetName,
+				TitleRowIndex: row,
+				EndRow:        row,
+			})
+			open = true
+			continue
+		}
+
+		if !open {
+			continue
+		}
+
+		if isBlankRow(column) {
+			open = false
+			continue
+		}
+		blocks[len(blocks)-1].EndRow = row
+	}
+
+	if err := rows.Error(); err != nil {
+		return nil, fmt.Errorf("excelstruct: rows: %w", err)
+	}
+	return blocks, nil
+}
+
+// matchBlock returns the block whose title is in the row.
+func (o *BlockOptions) matchBlock(row int, column []string) (BlockTitle, bool) {
+	exist := make(map[string]struct{}, len(column))
+	for _, v := range column {
+		exist[o.TitleConv(v)] = struct{}{}
+	}
+
+	for _, b := range o.Block {
+		if b.Anchor > 0 {
+			if b.Anchor == row {
+				return b, true
+			}
+			continue
+		}
+
+		match := true
+		for _, v := range b.Title {
+			if _, ok := exist[v]; !ok {
+				match = false
+				break
+			}
+		}
+
+		if match {
+			return b, true
+		}
+	}
+	return BlockTitle{}, false
+}
+
+// NewBlockDecoder creates a decoder of the block.
+func NewBlockDecoder[T any](r *Read, b Block, opts DecoderOptions) (*Decoder[T], error) {
+	opts.SheetName = b.SheetName
+	opts.TitleRowIndex = b.TitleRowIndex
+	if opts.DataEndRow == 0 || opts.DataEndRow > b.EndRow {
+		opts.DataEndRow = b.EndRow
+	}
+	return NewDecoder[T](r, opts)
+}
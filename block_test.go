@@ -0,0 +1,92 @@
+package excelstruct
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanBlocks(t *testing.T) {
+	t.Parallel()
+
+	type summary struct {
+		Region string `excel:"region"`
+		Total  int    `excel:"total"`
+	}
+
+	type detail struct {
+		Item string `excel:"item"`
+		Qty  int    `excel:"qty"`
+	}
+
+	f := newTestRead(t, [][]any{
+		{"Report"},
+		{"region", "total"},
+		{"EU", 10},
+		{"US", 20},
+		{},
+		{"item", "qty", "note"},
+		{"a", 1},
+		{"b", 2},
+		{"c", 3},
+		{"region", "total"},
+		{"ASIA", 30},
+	})
+
+	blocks, err := ScanBlocks(f, BlockOptions{
+		Block: []BlockTitle{BlockOf[summary]("summary"), BlockOf[detail]("detail")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []Block{
+		{Name: "summary", SheetName: DefaultSheetName, TitleRowIndex: 2, EndRow: 4},
+		{Name: "detail", SheetName: DefaultSheetName, TitleRowIndex: 6, EndRow: 9},
+		{Name: "summary", SheetName: DefaultSheetName, TitleRowIndex: 10, EndRow: 11},
+	}, blocks)
+
+	t.Run("decoder", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewBlockDecoder[detail](f, blocks[1], DecoderOptions{})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		var got []detail
+		require.NoError(t, sheet.All(&got))
+		assert.Equal(t, []detail{{"a", 1}, {"b", 2}, {"c", 3}}, got)
+	})
+
+	t.Run("anchor", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := ScanBlocks(f, BlockOptions{
+			Block: []BlockTitle{{Name: "summary", Anchor: 2}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []Block{{Name: "summary", SheetName: DefaultSheetName, TitleRowIndex: 2, EndRow: 4}}, got)
+	})
+
+	t.Run("options are not changed", func(t *testing.T) {
+		t.Parallel()
+
+		opts := BlockOptions{Block: []BlockTitle{BlockOf[summary]("summary")}}
+		for range 2 {
+			got, err := ScanBlocks(f, opts)
+			require.NoError(t, err)
+			assert.Len(t, got, 2)
+		}
+		assert.Empty(t, opts.Block[0].Title)
+	})
+
+	t.Run("titles by hand", func(t *testing.T) {
+		t.Parallel()
+
+		b := BlockOf[detail]("detail")
+		b.Title = []string{"item"}
+
+		got, err := ScanBlocks(f, BlockOptions{Block: []BlockTitle{b}})
+		require.NoError(t, err)
+		assert.Equal(t, []Block{{Name: "detail", SheetName: DefaultSheetName, TitleRowIndex: 6, EndRow: 11}}, got)
+		assert.Equal(t, []string{"item"}, b.Title)
+	})
+}
@@ -0,0 +1,129 @@
+package excelstruct
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// childrenField returns the field with the tag option "children".
+func childrenField(t reflect.Type, tag string) (*field, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	for _, f := range cachedTypeFields(t, typeOpts{structTag: tag}).list {
+		if f.meta != optChildren {
+			continue
+		}
+
+		if f.typ.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("field %q children requires slice", f.name)
+		}
+		return &f, nil
+	}
+	return nil, nil
+}
+
+// isChild reports whether the current row is the child row.
+func (c *Decoder[T]) isChild() (bool, error) {
+	return c.childRow(c.sheetName, c.cursor, c.dec.sheet.title)
+}
+
+// childRow reports whether the current row of the cursor is the child row.
+func (c *Decoder[T]) childRow(sheetName string, cursor *rowCursor, title *title) (bool, error) {
+	if c.opts.ChildOutline {
+		level, err := c.File.GetRowOutlineLevel(sheetName, cursor.row)
+		if err != nil {
+			return false, fmt.Errorf("row %d outline level: %w", cursor.row, err)
+		}
+
+		if level > 0 {
+			return true, nil
+		}
+	}
+
+	if c.opts.ChildMarker == "" && c.opts.ChildRow == nil {
+		return false, nil
+	}
+
+	column, err := cursor.Columns()
+	if err != nil {
+		return false, fmt.Errorf("get columns: %w", err)
+	}
+
+	if c.opts.ChildMarker != "" {
+		// the title is checked when the sheet is opened
+		col, _ := title.columnIndex(c.opts.ChildMarker)
+		if col[0] > len(column) || isEmptyString(strings.TrimSpace(column[col[0]-1])) {
+			return true, nil
+		}
+	}
+	return c.opts.ChildRow != nil && c.opts.ChildRow(column), nil
+}
+
+// decodeChildren decodes the child rows following the parent row into the children field.
+// The first row after the children is returned by the next call of Next.
+func (c *Decoder[T]) decodeChildren(res *T) error {
+	v, err := fieldByIndex(reflect.ValueOf(res).Elem(), c.children.index)
+	if err != nil {
+		return fmt.Errorf("excelstruct: field %q: %w", c.children.name, err)
+	}
+	v.SetZero()
+
+	var childErr error
+	for c.cursor.Next() {
+		child, err := c.isChild()
+		if err != nil {
+			return fmt.Errorf("excelstruct: %w", err)
+		}
+
+		if !child {
+			c.pending = true
+			break
+		}
+
+		column, err := c.cursor.Columns()
+		if err != nil {
+			return fmt.Errorf("excelstruct: get columns: %w", err)
+		}
+
+		elem := reflect.New(v.Type().Elem())
+		c.dec.row = c.cursor.row
+		if err := c.dec.unmarshal(column, elem.Interface()); err != nil && childErr == nil {
+			childErr = err
+		}
+		v.Set(reflect.Append(v, elem.Elem()))
+	}
+	return childErr
+}
+
+// encodeChildren writes the child rows after the parent row with the outline level 1.
+func (e *Encoder[T]) encodeChildren(v *T) error {
+	fv := reflect.ValueOf(v).Elem()
+	for _, i := range e.children.index {
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				return nil
+			}
+			fv = fv.Elem()
+		}
+		fv = fv.Field(i)
+	}
+
+	if fv.Len() > 0 && e.enc.orient != OrientationRow {
+		return fmt.Errorf("excelstruct: field %q children requires row orientation", e.children.name)
+	}
+
+	for i := 0; i < fv.Len(); i++ {
+		row := e.enc.row
+		if err := e.enc.marshal(fv.Index(i).Interface()); err != nil {
+			return err
+		}
+
+		if err := e.File.SetRowOutlineLevel(e.enc.title.config.sheetName, row, 1); err != nil {
+			return fmt.Errorf("excelstruct: row %d outline level: %w", row, err)
+		}
+	}
+	return nil
+}
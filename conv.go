@@ -0,0 +1,78 @@
+package excelstruct
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// parseConv returns the names of converters from the tag option "conv".
+func parseConv(opts tagOptions) []string {
+	v, ok := opts.Get(optConv)
+	if !ok || v == "" {
+		return nil
+	}
+	return strings.Split(v, convSeparator)
+}
+
+// read applies the read converters of the chain from left to right.
+func (nc NameConv) read(chain []string, v string) (string, error) {
+	for _, name := range chain {
+		c, ok := nc[name]
+		if !ok {
+			return "", fmt.Errorf("conv %q not found", name)
+		}
+
+		if c.Read == nil {
+			continue
+		}
+
+		var err error
+		if v, err = c.Read(v); err != nil {
+			return "", fmt.Errorf("conv %q: %w", name, err)
+		}
+	}
+	return v, nil
+}
+
+// write applies the write converters of the chain from right to left, so writing is inverse of reading.
+func (nc NameConv) write(chain []string, v string) (string, error) {
+	for i := len(chain) - 1; i >= 0; i-- {
+		c, ok := nc[chain[i]]
+		if !ok {
+			return "", fmt.Errorf("conv %q not found", chain[i])
+		}
+
+		if c.Write == nil {
+			continue
+		}
+
+		var err error
+		if v, err = c.Write(v); err != nil {
+			return "", fmt.Errorf("conv %q: %w", chain[i], err)
+		}
+	}
+	return v, nil
+}
+
+// check checks that all converters of struct fields are registered.
+func (nc NameConv) check(t reflect.Type, tag string) error {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for _, f := range cachedTypeFields(t, typeOpts{structTag: tag}).list {
+		for _, name := range f.conv {
+			if _, ok := nc[name]; !ok {
+				return fmt.Errorf("field %q conv %q not found", f.name, name)
+			}
+		}
+
+		if st, ok := groupStruct(f.typ); ok && f.group != "" {
+			if err := nc.check(st, tag); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
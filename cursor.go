@@ -0,0 +1,300 @@
+package excelstruct
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// rowIterator is the iterator over the rows of a sheet.
+type rowIterator interface {
+	Next() bool
+	Columns(opts ...excelize.Options) ([]string, error)
+}
+
+// mergedValue is the value of a merged cell covering the column.
+type mergedValue struct {
+	col   int
+	value string
+}
+
+// rowCursor is the cursor over the rows of a sheet which knows the current row number.
+// The rows up to the title row are returned as is, the data rows are filtered by the options.
+type rowCursor struct {
+	file       *excelize.File
+	sheetName  string
+	rows       *excelize.Rows
+	row        int
+	titleRow   int
+	startRow   int
+	endRow     int
+	offset     int
+	limit      int
+	firstCol   int
+	lastCol    int
+	count      int // number of returned data rows
+	skipHidden bool
+	blankRow   BlankRow
+	endOfData  EndOfData
+	skipped    []int                 // rows which were skipped
+	merged     map[int][]mergedValue // map[row]values, nil if merged cells are not propagated
+	done       bool
+
+	// columns of the current row are read once
+	read   bool
+	column []string
+	err    error
+}
+
+// newRowCursor opens the cursor of the sheet.
+func newRowCursor(file *excelize.File, sheetName string, opts DecoderOptions) (*rowCursor, error) {
+	rows, err := file.Rows(sheetName)
+	if err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	c := &rowCursor{
+		file:       file,
+		sheetName:  sheetName,
+		rows:       rows,
+		titleRow:   opts.TitleRowIndex,
+		startRow:   opts.DataStartRow,
+		endRow:     opts.DataEndRow,
+		offset:     opts.Offset,
+		limit:      opts.Limit,
+		firstCol:   opts.FirstColumn,
+		lastCol:    opts.LastColumn,
+		skipHidden: opts.SkipHiddenRows,
+		blankRow:   opts.BlankRow,
+		endOfData:  opts.EndOfData,
+	}
+	if opts.FillMergedCells {
+		if c.merged, err = readMergedCells(file, sheetName); err != nil {
+			rows.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Next moves the cursor to the next row.
+func (c *rowCursor) Next() bool {
+	if c.done {
+		return false
+	}
+
+	if c.limit > 0 && c.count >= c.limit {
+		c.done = true
+		return false
+	}
+
+	for c.rows.Next() {
+		c.row++
+		c.read = false
+		if c.row <= c.titleRow {
+			return true
+		}
+
+		if c.row < c.startRow {
+			continue
+		}
+
+		if c.endRow > 0 && c.row > c.endRow {
+			c.done = true
+			return false
+		}
+
+		if !c.filter() {
+			if c.done {
+				return false
+			}
+			continue
+		}
+
+		if c.offset > 0 {
+			c.offset--
+			continue
+		}
+
+		c.count++
+		return true
+	}
+	return false
+}
+
+// filter reports whether the current data row passes the options.
+func (c *rowCursor) filter() bool {
+	if c.skipHidden && c.rows.GetRowOpts().Hidden {
+		c.skipped = append(c.skipped, c.row)
+		return false
+	}
+
+	if c.blankRow == BlankRowKeep && c.endOfData == nil {
+		return true
+	}
+
+	column, err := c.Columns()
+	if err != nil {
+		// the error is returned by Columns
+		return true
+	}
+
+	if c.endOfData != nil && c.endOfData(column) {
+		c.done = true
+		return false
+	}
+
+	if isBlankRow(column) {
+		switch c.blankRow {
+		case BlankRowSkip:
+			c.skipped = append(c.skipped, c.row)
+			return false
+
+		case BlankRowStop:
+			c.done = true
+			return false
+		}
+	}
+	return true
+}
+
+// seek moves the cursor so the next call of Next returns the data row not less than the given row.
+// The offset is not applied again and the limit is counted from the row.
+func (c *rowCursor) seek(row int) error {
+	row = max(row, c.titleRow+1, c.startRow)
+	if row <= c.row {
+		rows, err := c.file.Rows(c.sheetName)
+		if err != nil {
+			return fmt.Errorf("rows: %w", err)
+		}
+
+		c.rows.Close()
+		c.rows = rows
+		c.row = 0
+	}
+
+	for c.row < row-1 && c.rows.Next() {
+		c.row++
+	}
+
+	c.read = false
+	c.done = false
+	c.offset = 0
+	c.count = 0
+	return nil
+}
+
+// Columns returns the values of the current row.
+func (c *rowCursor) Columns(opts ...excelize.Options) ([]string, error) {
+	if c.read {
+		return c.column, c.err
+	}
+
+	c.read = true
+	c.column, c.err = c.rows.Columns(opts...)
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	c.column = c.bound(c.fillMerged(c.column))
+	return c.column, nil
+}
+
+// bound clears the values outside the column bounds.
+func (c *rowCursor) bound(column []string) []string {
+	if c.lastCol > 0 && len(column) > c.lastCol {
+		column = column[:c.lastCol]
+	}
+
+	for i := 0; i < c.firstCol-1 && i < len(column); i++ {
+		column[i] = ""
+	}
+	return column
+}
+
+// Error returns the error when the error occurs.
+func (c *rowCursor) Error() error {
+	return c.rows.Error()
+}
+
+// Close closes the cursor.
+func (c *rowCursor) Close() error {
+	return c.rows.Close()
+}
+
+// seekTitle moves the cursor to the title row.
+func (c *rowCursor) seekTitle() bool {
+	for c.row < c.titleRow {
+		if !c.Next() {
+			return false
+		}
+	}
+	return true
+}
+
+// fillMerged fills the cells covered by a merged cell with its value.
+func (c *rowCursor) fillMerged(column []string) []string {
+	for _, m := range c.merged[c.row] {
+		for len(column) < m.col {
+			column = append(column, "")
+		}
+		column[m.col-1] = m.value
+	}
+	return column
+}
+
+// readMergedCells returns the values of merged cells by the rows which they cover.
+func readMergedCells(file *excelize.File, sheetName string) (map[int][]mergedValue, error) {
+	cells, err := file.GetMergeCells(sheetName)
+	if err != nil {
+		return nil, fmt.Errorf("merge cells: %w", err)
+	}
+
+	merged := make(map[int][]mergedValue)
+	for _, cell := range cells {
+		startCol, startRow, err := excelize.CellNameToCoordinates(cell.GetStartAxis())
+		if err != nil {
+			return nil, fmt.Errorf("merge cell %q: %w", cell.GetStartAxis(), err)
+		}
+
+		endCol, endRow, err := excelize.CellNameToCoordinates(cell.GetEndAxis())
+		if err != nil {
+			return nil, fmt.Errorf("merge cell %q: %w", cell.GetEndAxis(), err)
+		}
+
+		for row := startRow; row <= endRow; row++ {
+			for col := startCol; col <= endCol; col++ {
+				merged[row] = append(merged[row], mergedValue{col: col, value: cell.GetCellValue()})
+			}
+		}
+	}
+	return merged, nil
+}
+
+// isBlankRow reports whether all values of the row are empty.
+func isBlankRow(column []string) bool {
+	for _, v := range column {
+		if !isEmptyString(strings.TrimSpace(v)) {
+			return false
+		}
+	}
+	return true
+}
+
+// hiddenColumns returns the function reporting whether the column is hidden.
+func hiddenColumns(file *excelize.File, sheetName string) func(col int) bool {
+	hidden := make(map[int]bool)
+	return func(col int) bool {
+		v, ok := hidden[col]
+		if ok {
+			return v
+		}
+
+		name, _ := excelize.ColumnNumberToName(col)
+		visible, err := file.GetColVisible(sheetName, name)
+		v = err == nil && !visible
+		hidden[col] = v
+		return v
+	}
+}
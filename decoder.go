@@ -6,6 +6,13 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var (
+	cellsType = reflect.TypeOf(map[string]string(nil))
+	fontType  = reflect.TypeOf(excelize.Font{})
 )
 
 // An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
@@ -49,6 +56,33 @@ func (e *ConvertValueError) Error() string {
 	return fmt.Sprintf("excelstruct: cannot convert value %q into Go struct field %q: %v", e.Value, e.Field, e.Err)
 }
 
+// A FormulaError describes an error of the formula recalculation such as #DIV/0!.
+type FormulaError struct {
+	Cell    string
+	Formula string
+	Value   string // error value of Excel such as #DIV/0!
+	Field   string
+	Err     error
+}
+
+func (e *FormulaError) Error() string {
+	return fmt.Sprintf("excelstruct: cannot calculate formula %q of cell %q into Go struct field %q: %s", e.Formula, e.Cell, e.Field, e.Value)
+}
+
+// Unwrap returns the underlying error.
+func (e *FormulaError) Unwrap() error { return e.Err }
+
+// A ReferenceError describes a value which is not found in the referenced key column.
+type ReferenceError struct {
+	Value string
+	Field string
+	Ref   string // reference "Sheet.title" of the key column
+}
+
+func (e *ReferenceError) Error() string {
+	return fmt.Sprintf("excelstruct: value %q of Go struct field %q not found in %q", e.Value, e.Field, e.Ref)
+}
+
 // An UnmarshalError describes an error that was occurred during unmarshal.
 type UnmarshalError struct {
 	Row int
@@ -81,6 +115,39 @@ func (e *UnmarshalError) AsConvertValueError() []ConvertValueError {
 	return res
 }
 
+// AsFormulaError returns the all FormulaError in UnmarshalError.
+func (e *UnmarshalError) AsFormulaError() []FormulaError {
+	var res []FormulaError
+	for _, v := range e.Err {
+		if err := new(FormulaError); errors.As(v, &err) {
+			res = append(res, *err)
+		}
+	}
+	return res
+}
+
+// AsReferenceError returns the all ReferenceError in UnmarshalError.
+func (e *UnmarshalError) AsReferenceError() []ReferenceError {
+	var res []ReferenceError
+	for _, v := range e.Err {
+		if err := new(ReferenceError); errors.As(v, &err) {
+			res = append(res, *err)
+		}
+	}
+	return res
+}
+
+// AsDuplicateKeyError returns the all DuplicateKeyError in UnmarshalError.
+func (e *UnmarshalError) AsDuplicateKeyError() []DuplicateKeyError {
+	var res []DuplicateKeyError
+	for _, v := range e.Err {
+		if err := new(DuplicateKeyError); errors.As(v, &err) {
+			res = append(res, *err)
+		}
+	}
+	return res
+}
+
 // Error returns the all error in UnmarshalError.
 func (e *UnmarshalError) Error() string {
 	causes := make([]string, 0, 2)
@@ -101,11 +168,23 @@ type decOpts struct {
 	stringConv ReadStringConv
 	boolConv   ReadBoolConv
 	timeConv   ReadTimeConv
+	nameConv   NameConv
+	formula    Formula
+	refs       refKeys // keys of the referenced columns, read once before decoding
+	form       bool    // the values are read from the form, the fields which need the cell of the row are not filled
+}
+
+// sheetState is the state of the sheet being decoded.
+type sheetState struct {
+	name     string
+	title    *title
+	file     *excelize.File
+	comments map[string]string // map[cell]text, nil if comments are not read
 }
 
 type decodeState struct {
 	opts     decOpts
-	title    *title
+	sheet    *sheetState
 	field    string
 	row      int
 	colIndex int
@@ -118,12 +197,7 @@ func (d *decodeState) unmarshal(item []string, v any) error {
 		return &InvalidUnmarshalError{reflect.TypeOf(v)}
 	}
 
-	if err := d.value(item, rv); err != nil {
-		return err
-	}
-
-	d.row++
-	return nil
+	return d.value(item, rv)
 }
 
 func (d *decodeState) value(item []string, v reflect.Value) error {
@@ -253,6 +327,22 @@ func (d *decodeState) literalStore(item string, v reflect.Value) error {
 	return nil
 }
 
+// convert applies the field converters to the item.
+func (d *decodeState) convert(f *field, item []string) error {
+	for i, v := range item {
+		nv, err := d.opts.nameConv.read(f.conv, v)
+		if err != nil {
+			return &ConvertValueError{
+				Value: v,
+				Field: f.name,
+				Err:   err,
+			}
+		}
+		item[i] = nv
+	}
+	return nil
+}
+
 func (d *decodeState) time(item string, v reflect.Value) error {
 	et, err := d.opts.timeConv(item)
 	if err != nil {
@@ -295,50 +385,93 @@ func (d *decodeState) object(data []string, v reflect.Value) error {
 	fields := cachedTypeFields(t, typeOpts{structTag: d.opts.tag})
 
 	for i := range fields.list {
-		col, ok := d.title.columnIndex(fields.list[i].name)
-		if !ok {
+		f := &fields.list[i]
+		if f.meta == optChildren || f.meta == optSection {
+			// filled by the decoder of rows or the form
 			continue
 		}
 
-		item := make([]string, 0, len(col))
-		for _, v := range col {
-			// empty value doesn't include item column, so skip index out of range
-			if v-1 >= len(data) {
+		if d.opts.form && (f.meta != "" || f.attr != "") {
+			// the form has no row of the cells
+			continue
+		}
+
+		if f.meta != "" {
+			subv, err := fieldByIndex(v, f.index)
+			if err != nil {
+				unmarshalError.saveError(err)
 				continue
 			}
 
-			d := strings.TrimSpace(data[v-1])
-			if isEmptyString(d) {
+			d.field = f.name
+			if err := d.meta(f.meta, subv); err != nil {
+				unmarshalError.saveError(err)
+			}
+			continue
+		}
+
+		if f.group != "" {
+			subv, err := fieldByIndex(v, f.index)
+			if err != nil {
+				unmarshalError.saveError(err)
 				continue
 			}
-			item = append(item, d)
+
+			d.group(f, data, subv, unmarshalError)
+			continue
 		}
 
-		subv := v
-		f := &fields.list[i]
-		for _, i := range f.index {
-			if subv.Kind() == reflect.Pointer {
-				if subv.IsNil() {
-					// If a struct embeds a pointer to an unexported type,
-					// it is not possible to set a newly allocated value
-					// since the field is unexported.
-					//
-					// See https://golang.org/issue/21357
-					if !subv.CanSet() {
-						unmarshalError.saveError(fmt.Errorf("exelstruct: cannot set embedded pointer to unexported struct: %v", subv.Type().Elem()))
-						// Invalidate subv to ensure d.value(subv) skips over
-						// the value without assigning it to subv.
-						subv = reflect.Value{}
-						break
-					}
-					subv.Set(reflect.New(subv.Type().Elem()))
-				}
-				subv = subv.Elem()
+		col, ok := d.sheet.title.columnIndex(f.name)
+		if !ok {
+			continue
+		}
+
+		if f.attr != "" {
+			subv, err := fieldByIndex(v, f.index)
+			if err != nil {
+				unmarshalError.saveError(err)
+				continue
 			}
-			subv = subv.Field(i)
+
+			d.field = f.name
+			d.col = col
+			if err := d.cellAttr(f, subv); err != nil {
+				unmarshalError.saveError(err)
+			}
+			continue
+		}
+
+		formula := f.formula
+		if formula == "" || d.opts.form {
+			formula = d.opts.formula
 		}
 
 		d.field = f.name
+		item, err := d.columnValues(col, data, formula)
+		if err != nil {
+			unmarshalError.saveError(err)
+			continue
+		}
+
+		if len(f.conv) > 0 {
+			if err := d.convert(f, item); err != nil {
+				unmarshalError.saveError(err)
+				continue
+			}
+		}
+
+		if f.ref != "" {
+			for _, err := range d.opts.refs.check(f, item) {
+				unmarshalError.saveError(err)
+			}
+		}
+
+		subv, err := fieldByIndex(v, f.index)
+		if err != nil {
+			unmarshalError.saveError(err)
+			continue
+		}
+
 		d.col = col
 		if err := d.value(item, subv); err != nil {
 			unmarshalError.saveError(err)
@@ -351,6 +484,281 @@ func (d *decodeState) object(data []string, v reflect.Value) error {
 	return nil
 }
 
+// fieldByIndex returns the nested field of the struct allocating pointers as needed.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for _, i := range index {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				// If a struct embeds a pointer to an unexported type,
+				// it is not possible to set a newly allocated value
+				// since the field is unexported.
+				//
+				// See https://golang.org/issue/21357
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("exelstruct: cannot set embedded pointer to unexported struct: %v", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v, nil
+}
+
+// meta decodes the metadata of the row into v.
+func (d *decodeState) meta(meta string, v reflect.Value) error {
+	_, v = indirect(v)
+	switch meta {
+	case optSheet:
+		if v.Kind() != reflect.String {
+			return &UnmarshalTypeError{Value: d.sheet.name, Type: v.Type(), Field: d.field}
+		}
+		v.SetString(d.sheet.name)
+
+	case optRow:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			v.SetInt(int64(d.row))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			v.SetUint(uint64(d.row))
+		default:
+			return &UnmarshalTypeError{Value: strconv.Itoa(d.row), Type: v.Type(), Field: d.field}
+		}
+
+	case optCells:
+		if v.Type() != cellsType {
+			return &UnmarshalTypeError{Value: "cells", Type: v.Type(), Field: d.field}
+		}
+
+		cells := make(map[string]string, len(d.sheet.title.name))
+		for _, n := range d.sheet.title.name {
+			if n.Name == "" {
+				continue
+			}
+
+			cell, err := excelize.CoordinatesToCellName(n.Column[0], d.row)
+			if err != nil {
+				return fmt.Errorf("excelstruct: field %q: %w", d.field, err)
+			}
+			cells[n.Name] = cell
+		}
+		v.Set(reflect.ValueOf(cells))
+	}
+	return nil
+}
+
+// columnValues returns the non-empty values of the columns.
+func (d *decodeState) columnValues(col []int, data []string, formula Formula) ([]string, error) {
+	item := make([]string, 0, len(col))
+	for _, v := range col {
+		var value string
+		// empty value doesn't include item column, so skip index out of range
+		if v-1 < len(data) {
+			value = data[v-1]
+		}
+
+		if formula != FormulaCached {
+			var err error
+			if value, err = d.formulaValue(v, value, formula); err != nil {
+				return nil, err
+			}
+		}
+
+		value = strings.TrimSpace(value)
+		if isEmptyString(value) {
+			continue
+		}
+		item = append(item, value)
+	}
+	return item, nil
+}
+
+// formulaValue returns the text or the recalculated value of the formula cell by the column,
+// the cached value is returned if the cell has no formula.
+func (d *decodeState) formulaValue(col int, cached string, formula Formula) (string, error) {
+	cell, err := excelize.CoordinatesToCellName(col, d.row)
+	if err != nil {
+		return "", fmt.Errorf("excelstruct: field %q: %w", d.field, err)
+	}
+
+	text, err := d.sheet.file.GetCellFormula(d.sheet.name, cell)
+	if err != nil {
+		return "", fmt.Errorf("excelstruct: field %q cell %q formula: %w", d.field, cell, err)
+	}
+
+	if text == "" {
+		return cached, nil
+	}
+
+	switch formula {
+	case FormulaText:
+		return text, nil
+
+	case FormulaCalc:
+		value, err := d.sheet.file.CalcCellValue(d.sheet.name, cell)
+		if err != nil {
+			if value == "" {
+				// the error value is returned as the error
+				value = err.Error()
+			}
+
+			return "", &FormulaError{
+				Cell:    cell,
+				Formula: text,
+				Value:   value,
+				Field:   d.field,
+				Err:     err,
+			}
+		}
+		return value, nil
+	}
+	return cached, nil
+}
+
+// cellAttr decodes the attribute of the cells instead of the value into v.
+func (d *decodeState) cellAttr(f *field, v reflect.Value) error {
+	if f.attr == optFont {
+		if _, pv := indirect(v); pv.Type() == fontType {
+			style, err := d.cellStyle(d.col[0])
+			if err != nil {
+				return err
+			}
+
+			if style.Font != nil {
+				pv.Set(reflect.ValueOf(*style.Font))
+			}
+			return nil
+		}
+	}
+
+	item := make([]string, 0, len(d.col))
+	for _, col := range d.col {
+		value, err := d.attrValue(f.attr, col)
+		if err != nil {
+			return err
+		}
+
+		if isEmptyString(value) {
+			continue
+		}
+		item = append(item, value)
+	}
+
+	if len(f.conv) > 0 {
+		if err := d.convert(f, item); err != nil {
+			return err
+		}
+	}
+	return d.value(item, v)
+}
+
+// attrValue returns the attribute of the cell by the column of the current row.
+func (d *decodeState) attrValue(attr string, col int) (string, error) {
+	cell, err := excelize.CoordinatesToCellName(col, d.row)
+	if err != nil {
+		return "", fmt.Errorf("excelstruct: field %q: %w", d.field, err)
+	}
+
+	switch attr {
+	case optComment:
+		return d.sheet.comments[cell], nil
+
+	case optHyperlink:
+		_, target, err := d.sheet.file.GetCellHyperLink(d.sheet.name, cell)
+		if err != nil {
+			return "", fmt.Errorf("excelstruct: field %q cell %q hyperlink: %w", d.field, cell, err)
+		}
+		return target, nil
+
+	case optFill:
+		style, err := d.cellStyle(col)
+		if err != nil {
+			return "", err
+		}
+
+		if len(style.Fill.Color) == 0 {
+			return "", nil
+		}
+		return style.Fill.Color[0], nil
+
+	case optFont:
+		style, err := d.cellStyle(col)
+		if err != nil {
+			return "", err
+		}
+
+		if style.Font == nil {
+			return "", nil
+		}
+		return style.Font.Color, nil
+
+	case optFormula:
+		formula, err := d.sheet.file.GetCellFormula(d.sheet.name, cell)
+		if err != nil {
+			return "", fmt.Errorf("excelstruct: field %q cell %q formula: %w", d.field, cell, err)
+		}
+		return formula, nil
+	}
+	return "", fmt.Errorf("excelstruct: field %q unknown attribute %q", d.field, attr)
+}
+
+// cellStyle returns the style of the cell by the column of the current row.
+func (d *decodeState) cellStyle(col int) (*excelize.Style, error) {
+	cell, err := excelize.CoordinatesToCellName(col, d.row)
+	if err != nil {
+		return nil, fmt.Errorf("excelstruct: field %q: %w", d.field, err)
+	}
+
+	idx, err := d.sheet.file.GetCellStyle(d.sheet.name, cell)
+	if err != nil {
+		return nil, fmt.Errorf("excelstruct: field %q cell %q style: %w", d.field, cell, err)
+	}
+
+	style, err := d.sheet.file.GetStyle(idx)
+	if err != nil {
+		return nil, fmt.Errorf("excelstruct: field %q cell %q style: %w", d.field, cell, err)
+	}
+	return style, nil
+}
+
+// readComments returns the text of comments by cell.
+func readComments(file *excelize.File, sheetName string) (map[string]string, error) {
+	comments, err := file.GetComments(sheetName)
+	if err != nil {
+		return nil, fmt.Errorf("comments: %w", err)
+	}
+
+	res := make(map[string]string, len(comments))
+	for _, c := range comments {
+		text := c.Text
+		if text == "" {
+			var b strings.Builder
+			for _, p := range c.Paragraph {
+				b.WriteString(p.Text)
+			}
+			text = b.String()
+		}
+		res[c.Cell] = text
+	}
+	return res, nil
+}
+
+// fieldAttrs returns the cell attributes used by the struct fields.
+func fieldAttrs(t reflect.Type, tag string) map[string]bool {
+	attrs := make(map[string]bool)
+	if t.Kind() != reflect.Struct {
+		return attrs
+	}
+
+	for _, f := range cachedTypeFields(t, typeOpts{structTag: tag}).list {
+		if f.attr != "" {
+			attrs[f.attr] = true
+		}
+	}
+	return attrs
+}
+
 // indirect walks down v allocating pointers as needed, until it gets to a non-pointer.
 // If it encounters an Unmarshaler, indirect stops and returns that.
 func indirect(v reflect.Value) (ValueUnmarshaler, reflect.Value) {
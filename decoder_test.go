@@ -1,7 +1,11 @@
 package excelstruct
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"testing"
@@ -9,6 +13,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
 )
 
 var (
@@ -493,6 +498,1023 @@ func TestDecoder_Count(t *testing.T) {
 		want := 3
 		assert.Equal(t, want, sheet.Count())
 	})
+
+	type v struct {
+		N string `excel:"n"`
+	}
+
+	rows := [][]any{{"n"}, {"a"}, {nil}, {"b"}, {"c"}, {"d"}}
+	open := func(t *testing.T) *Read {
+		r := newTestRead(t, rows)
+		require.NoError(t, r.SetSheetDimension(DefaultSheetName, "A1:A6"))
+
+		b, err := r.WriteToBuffer()
+		require.NoError(t, err)
+
+		f, err := OpenBytes(b.Bytes(), OpenFileOptions{})
+		require.NoError(t, err)
+		t.Cleanup(func() { f.Close() })
+		return f
+	}
+
+	t.Run("dimension", func(t *testing.T) {
+		t.Parallel()
+
+		f := open(t)
+		sheet, err := NewDecoder[v](f, DecoderOptions{DataStartRow: 3, Offset: 1})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		assert.Equal(t, 3, sheet.Count())
+		assert.Equal(t, 3, sheet.Count())
+
+		_, loaded := f.Sheet.Load("xl/worksheets/sheet1.xml")
+		assert.False(t, loaded)
+	})
+
+	t.Run("dimension of temporary part", func(t *testing.T) {
+		t.Parallel()
+
+		r := newTestRead(t, rows)
+		// the dimension is larger than the rows to tell it from scanning
+		require.NoError(t, r.SetSheetDimension(DefaultSheetName, "A1:A9"))
+
+		b, err := r.WriteToBuffer()
+		require.NoError(t, err)
+
+		// the worksheet is kept in a temporary file by excelize
+		opts := OpenFileOptions{Excel: &excelize.Options{UnzipXMLSizeLimit: 1}}
+
+		path := filepath.Join(t.TempDir(), "dimension.xlsx")
+		require.NoError(t, os.WriteFile(path, b.Bytes(), 0o600))
+
+		for name, open := range map[string]func() (*Read, error){
+			"bytes": func() (*Read, error) { return OpenBytes(b.Bytes(), opts) },
+			"file": func() (*Read, error) {
+				o := opts
+				o.FilePath = path
+				return OpenFile(o)
+			},
+			"fs": func() (*Read, error) {
+				o := opts
+				o.FilePath = filepath.Base(path)
+				return OpenFS(os.DirFS(filepath.Dir(path)), o)
+			},
+			"reader": func() (*Read, error) { return OpenReader(bytes.NewReader(b.Bytes()), opts) },
+		} {
+			f, err := open()
+			require.NoError(t, err, name)
+			defer f.Close()
+
+			_, ok := f.Pkg.Load("xl/worksheets/sheet1.xml")
+			require.False(t, ok, name)
+
+			sheet, err := NewDecoder[v](f, DecoderOptions{DataStartRow: 3, Offset: 1})
+			require.NoError(t, err, name)
+			defer sheet.Close()
+
+			want := 6
+			if name == "reader" {
+				want = 3 // the source is not kept, the rows are scanned
+			}
+			assert.Equal(t, want, sheet.Count(), name)
+		}
+	})
+
+	t.Run("blank row", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](open(t), DecoderOptions{BlankRow: BlankRowSkip, DataEndRow: 5})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		assert.Equal(t, 3, sheet.Count())
+	})
+}
+
+func TestUnmarshal_NameConv(t *testing.T) {
+	t.Parallel()
+
+	type v struct {
+		Country string `excel:"country,conv=trim|upper"`
+		Active  bool   `excel:"active,conv=yn"`
+	}
+
+	yn := ValueConv{
+		Read: func(v string) (string, error) {
+			switch v {
+			case "Y":
+				return "true", nil
+			case "N":
+				return "false", nil
+			}
+			return "", fmt.Errorf("unknown value")
+		},
+	}
+
+	f := newTestRead(t, [][]any{
+		{"country", "active"},
+		{" de ", "Y"},
+		{"fr", "N"},
+		{"it", "-"},
+	})
+
+	sheet, err := NewDecoder[v](f, DecoderOptions{Conv: NameConv{"yn": yn}})
+	require.NoError(t, err)
+	defer sheet.Close()
+
+	var got []v
+	for sheet.Next() {
+		var row v
+		if err := sheet.Decode(&row); err != nil {
+			convertErr := new(UnmarshalError)
+			require.ErrorAs(t, err, &convertErr)
+			assert.Equal(t, "-", convertErr.AsConvertValueError()[0].Value)
+			continue
+		}
+		got = append(got, row)
+	}
+	assert.Equal(t, []v{{Country: "DE", Active: true}, {Country: "FR"}}, got)
+}
+
+func TestDecoder_FillMergedCells(t *testing.T) {
+	t.Parallel()
+
+	type v struct {
+		Region string   `excel:"region"`
+		Name   string   `excel:"name"`
+		Phone  []string `excel:"phone"`
+	}
+
+	f := newTestRead(t, [][]any{
+		{"region", "name", "phone", "phone"},
+		{"EU", "a", "1"},
+		{nil, "b", "2", "3"},
+		{nil, "c"},
+	})
+	require.NoError(t, f.MergeCell(DefaultSheetName, "A2", "A4"))
+	require.NoError(t, f.MergeCell(DefaultSheetName, "C2", "D2"))
+
+	t.Run("fill", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{FillMergedCells: true})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		var got []v
+		require.NoError(t, sheet.All(&got))
+		assert.Equal(t, []v{
+			{Region: "EU", Name: "a", Phone: []string{"1", "1"}},
+			{Region: "EU", Name: "b", Phone: []string{"2", "3"}},
+			{Region: "EU", Name: "c"},
+		}, got)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		var got []v
+		require.NoError(t, sheet.All(&got))
+		assert.Equal(t, []v{
+			{Region: "EU", Name: "a", Phone: []string{"1"}},
+			{Name: "b", Phone: []string{"2", "3"}},
+			{Name: "c"},
+		}, got)
+	})
+}
+
+func TestDecoder_SkipHidden(t *testing.T) {
+	t.Parallel()
+
+	type v struct {
+		Name string `excel:"name"`
+		Code string `excel:"code"`
+	}
+
+	f := newTestRead(t, [][]any{
+		{"name", "code"},
+		{"a", "1"},
+		{"b", "2"},
+		{"c", "3"},
+	})
+	require.NoError(t, f.SetRowVisible(DefaultSheetName, 3, false))
+	require.NoError(t, f.SetColVisible(DefaultSheetName, "B", false))
+
+	sheet, err := NewDecoder[v](f, DecoderOptions{SkipHiddenRows: true, SkipHiddenColumns: true})
+	require.NoError(t, err)
+	defer sheet.Close()
+
+	var got []v
+	require.NoError(t, sheet.All(&got))
+	assert.Equal(t, []v{{Name: "a"}, {Name: "c"}}, got)
+	assert.Equal(t, []SkippedRow{{DefaultSheetName, 3}}, sheet.SkippedRows())
+	assert.Equal(t, 2, sheet.Count())
+}
+
+func TestDecoder_SkipHiddenSheets(t *testing.T) {
+	t.Parallel()
+
+	type v struct {
+		Name string `excel:"name"`
+	}
+
+	f := newTestRead(t, nil)
+	for _, name := range []string{"Jan", "Feb"} {
+		_, err := f.NewSheet(name)
+		require.NoError(t, err)
+
+		for i, row := range [][]any{{"name"}, {"a"}, {"b"}, {"c"}} {
+			require.NoError(t, f.SetSheetRow(name, fmt.Sprintf("A%d", i+1), &row))
+		}
+		require.NoError(t, f.SetRowVisible(name, 4, false))
+	}
+
+	sheet, err := NewDecoder[v](f, DecoderOptions{SheetNames: []string{"Jan", "Feb"}, SkipHiddenRows: true})
+	require.NoError(t, err)
+	defer sheet.Close()
+
+	var got []v
+	require.NoError(t, sheet.All(&got))
+	assert.Equal(t, []v{{"a"}, {"b"}, {"a"}, {"b"}}, got)
+	assert.Equal(t, []SkippedRow{{"Jan", 4}, {"Feb", 4}}, sheet.SkippedRows())
+}
+
+func TestDecoder_BlankRow(t *testing.T) {
+	t.Parallel()
+
+	type v struct {
+		Name  string `excel:"name"`
+		Total int    `excel:"total"`
+	}
+
+	f := newTestRead(t, [][]any{
+		{"name", "total"},
+		{"a", 1},
+		{nil, " "},
+		{"b", 2},
+		{"Total", 3},
+		{},
+	})
+	style, err := f.NewStyle(&excelize.Style{Fill: excelize.Fill{Type: "pattern", Color: []string{"FFFF00"}, Pattern: 1}})
+	require.NoError(t, err)
+	require.NoError(t, f.SetCellStyle(DefaultSheetName, "A6", "B7", style)) // formatted but empty rows
+
+	for _, tt := range []struct {
+		name    string
+		opts    DecoderOptions
+		want    []v
+		skipped []SkippedRow
+	}{
+		{
+			name: "keep",
+			opts: DecoderOptions{},
+			want: []v{{"a", 1}, {}, {"b", 2}, {"Total", 3}, {}, {}},
+		},
+		{
+			name:    "skip",
+			opts:    DecoderOptions{BlankRow: BlankRowSkip},
+			want:    []v{{"a", 1}, {"b", 2}, {"Total", 3}},
+			skipped: []SkippedRow{{DefaultSheetName, 3}, {DefaultSheetName, 6}, {DefaultSheetName, 7}},
+		},
+		{
+			name: "stop",
+			opts: DecoderOptions{BlankRow: BlankRowStop},
+			want: []v{{"a", 1}},
+		},
+		{
+			name: "end of data",
+			opts: DecoderOptions{
+				BlankRow: BlankRowSkip,
+				EndOfData: func(column []string) bool {
+					return len(column) > 0 && column[0] == "Total"
+				},
+			},
+			want:    []v{{"a", 1}, {"b", 2}},
+			skipped: []SkippedRow{{DefaultSheetName, 3}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sheet, err := NewDecoder[v](f, tt.opts)
+			require.NoError(t, err)
+			defer sheet.Close()
+
+			var got []v
+			require.NoError(t, sheet.All(&got))
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.skipped, sheet.SkippedRows())
+			assert.Equal(t, len(tt.want), sheet.Count())
+		})
+	}
+}
+
+func TestDecoder_Range(t *testing.T) {
+	t.Parallel()
+
+	type v struct {
+		N int `excel:"n"`
+	}
+
+	rows := [][]any{{"n"}}
+	for i := 2; i <= 11; i++ {
+		rows = append(rows, []any{i})
+	}
+	f := newTestRead(t, rows)
+
+	decode := func(t *testing.T, sheet *Decoder[v]) []int {
+		var got []int
+		for sheet.Next() {
+			var row v
+			require.NoError(t, sheet.Decode(&row))
+			got = append(got, row.N)
+		}
+		return got
+	}
+
+	t.Run("start end", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{DataStartRow: 4, DataEndRow: 6})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		assert.Equal(t, []int{4, 5, 6}, decode(t, sheet))
+		assert.Equal(t, 3, sheet.Count())
+	})
+
+	t.Run("offset limit", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{Offset: 2, Limit: 3})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		assert.Equal(t, []int{4, 5, 6}, decode(t, sheet))
+		assert.Equal(t, 3, sheet.Count())
+	})
+
+	t.Run("seek skip", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		require.NoError(t, sheet.Seek(8))
+		assert.Equal(t, 2, sheet.Skip(2))
+		assert.Equal(t, []int{10, 11}, decode(t, sheet))
+
+		require.NoError(t, sheet.Seek(1))
+		assert.Equal(t, 10, sheet.Skip(20))
+	})
+
+	t.Run("seek limit", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{Limit: 2})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		assert.Equal(t, []int{2, 3}, decode(t, sheet))
+
+		require.NoError(t, sheet.Seek(2))
+		assert.Equal(t, []int{2, 3}, decode(t, sheet))
+
+		require.NoError(t, sheet.Seek(9))
+		assert.Equal(t, []int{9, 10}, decode(t, sheet))
+	})
+}
+
+func TestDecoder_Area(t *testing.T) {
+	t.Parallel()
+
+	type v struct {
+		Name  string `excel:"name"`
+		Total int    `excel:"total"`
+	}
+
+	f := newTestRead(t, [][]any{
+		{"report"},
+		{"name", "x", "name", "total", "note"},
+		{"skip", "x", "a", 1, "n"},
+		{"skip", "x", "b", 2, "n"},
+		{"footer", "x", "c", 3},
+	})
+	require.NoError(t, f.AddTable(DefaultSheetName, &excelize.Table{Range: "C2:D4", Name: "Sales"}))
+	require.NoError(t, f.SetDefinedName(&excelize.DefinedName{Name: "SalesRange", RefersTo: "'Sheet1'!$C$2:$D$3"}))
+
+	t.Run("table", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{TableName: "sales"})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		var got []v
+		require.NoError(t, sheet.All(&got))
+		assert.Equal(t, []v{{"a", 1}, {"b", 2}}, got)
+		assert.Equal(t, 2, sheet.Count())
+	})
+
+	t.Run("defined name", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{DefinedName: "SalesRange"})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		var got []v
+		require.NoError(t, sheet.All(&got))
+		assert.Equal(t, []v{{"a", 1}}, got)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewDecoder[v](f, DecoderOptions{TableName: "unknown"})
+		assert.EqualError(t, err, `excelstruct: table "unknown" not found`)
+	})
+}
+
+func TestDecoder_Rows(t *testing.T) {
+	t.Parallel()
+
+	type v struct {
+		N int `excel:"n"`
+	}
+
+	f := newTestRead(t, [][]any{{"n"}, {1}, {"x"}, {3}, {4}})
+
+	t.Run("continue on error", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{})
+		require.NoError(t, err)
+
+		var (
+			got  []Row[v]
+			errs int
+		)
+		for row, err := range sheet.IndexedRows() {
+			if err != nil {
+				errs++
+				continue
+			}
+			got = append(got, row)
+		}
+		assert.Equal(t, 1, errs)
+		assert.Equal(t, []Row[v]{{2, v{1}}, {4, v{3}}, {5, v{4}}}, got)
+	})
+
+	t.Run("break", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{})
+		require.NoError(t, err)
+
+		var got []v
+		for row, err := range sheet.Rows() {
+			require.NoError(t, err)
+			got = append(got, row)
+			break
+		}
+		assert.Equal(t, []v{{1}}, got)
+	})
+}
+
+func TestDecoder_Sheets(t *testing.T) {
+	t.Parallel()
+
+	type v struct {
+		Name  string `excel:"name"`
+		Total int    `excel:"total"`
+		Sheet string `excel:",sheet"`
+	}
+
+	f := newTestRead(t, [][]any{
+		{"name", "total"},
+		{"a", 1},
+	})
+	for name, rows := range map[string][][]any{
+		"Jan":   {{"total", "name"}, {2, "b"}, {3, "c"}},
+		"Feb":   {{"name", "total"}, {"d", 4}},
+		"Total": {{"name"}, {"e"}},
+	} {
+		_, err := f.NewSheet(name)
+		require.NoError(t, err)
+
+		for i, row := range rows {
+			require.NoError(t, f.SetSheetRow(name, fmt.Sprintf("A%d", i+1), &row))
+		}
+	}
+
+	t.Run("names", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{SheetNames: []string{"Feb", "Jan"}})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		var got []v
+		require.NoError(t, sheet.All(&got))
+		assert.Equal(t, []v{{"d", 4, "Feb"}, {"b", 2, "Jan"}, {"c", 3, "Jan"}}, got)
+		assert.Equal(t, 3, sheet.Count())
+	})
+
+	t.Run("pattern", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{SheetPattern: "?e*"})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		var got []v
+		require.NoError(t, sheet.All(&got))
+		assert.Equal(t, []v{{"d", 4, "Feb"}}, got)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewDecoder[v](f, DecoderOptions{SheetNames: []string{"Mar"}})
+		assert.EqualError(t, err, `excelstruct: sheet "Mar" not found`)
+	})
+}
+
+func TestUnmarshal_Meta(t *testing.T) {
+	t.Parallel()
+
+	type v struct {
+		Name  string            `excel:"name"`
+		Row   int               `excel:",row"`
+		Sheet *string           `excel:",sheet"`
+		Cells map[string]string `excel:",cells"`
+	}
+
+	f := newTestRead(t, [][]any{
+		{"report"},
+		{"code", "name"},
+		{1, "a"},
+		{2, "b"},
+	})
+
+	sheet, err := NewDecoder[v](f, DecoderOptions{TitleRowIndex: 2})
+	require.NoError(t, err)
+	defer sheet.Close()
+
+	var got []v
+	require.NoError(t, sheet.All(&got))
+	assert.Equal(t, []v{
+		{Name: "a", Row: 3, Sheet: ptrV(DefaultSheetName), Cells: map[string]string{"code": "A3", "name": "B3"}},
+		{Name: "b", Row: 4, Sheet: ptrV(DefaultSheetName), Cells: map[string]string{"code": "A4", "name": "B4"}},
+	}, got)
+
+	t.Run("type", func(t *testing.T) {
+		t.Parallel()
+
+		type v struct {
+			Row string `excel:",row"`
+		}
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{TitleRowIndex: 2})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		require.True(t, sheet.Next())
+		var row v
+		typeErr := new(UnmarshalError)
+		require.ErrorAs(t, sheet.Decode(&row), &typeErr)
+		assert.Equal(t, "3", typeErr.AsTypeError()[0].Value)
+	})
+}
+
+func TestUnmarshal_Group(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		Name string `excel:"Name"`
+		Qty  int    `excel:"Qty"`
+	}
+
+	type v struct {
+		Order string  `excel:"Order"`
+		Items []item  `excel:"items,group=Item{n} "`
+		Ptr   []*item `excel:"ptr,group=Item{n} "`
+	}
+
+	f := newTestRead(t, [][]any{
+		{"Order", "Item1 Name", "Item1 Qty", "Item2 Name", "Item2 Qty", "Item3 Name", "Item3 Qty"},
+		{"a", "pen", 2, "", "", "book", 1},
+		{"b", "cup", 3},
+		{"c"},
+	})
+
+	sheet, err := NewDecoder[v](f, DecoderOptions{})
+	require.NoError(t, err)
+	defer sheet.Close()
+
+	var got []v
+	require.NoError(t, sheet.All(&got))
+	assert.Equal(t, []v{
+		{
+			Order: "a",
+			Items: []item{{Name: "pen", Qty: 2}, {}, {Name: "book", Qty: 1}},
+			Ptr:   []*item{{Name: "pen", Qty: 2}, {}, {Name: "book", Qty: 1}},
+		},
+		{Order: "b", Items: []item{{Name: "cup", Qty: 3}}, Ptr: []*item{{Name: "cup", Qty: 3}}},
+		{Order: "c"},
+	}, got)
+}
+
+func TestDecoder_Children(t *testing.T) {
+	t.Parallel()
+
+	type child struct {
+		Name string `excel:"name"`
+		Qty  int    `excel:"qty"`
+		Row  int    `excel:",row"`
+	}
+
+	type parent struct {
+		Code     string  `excel:"code"`
+		Name     string  `excel:"name"`
+		Children []child `excel:",children"`
+	}
+
+	want := []parent{
+		{Code: "p1", Name: "bike", Children: []child{{Name: "wheel", Qty: 2, Row: 3}, {Name: "frame", Qty: 1, Row: 4}}},
+		{Code: "p2", Name: "chair"},
+		{Code: "p3", Name: "table", Children: []child{{Name: "leg", Qty: 4, Row: 7}}},
+	}
+
+	f := newTestRead(t, [][]any{
+		{"code", "name", "qty"},
+		{"p1", "bike"},
+		{"", "wheel", 2},
+		{"", "frame", 1},
+		{"p2", "chair"},
+		{"p3", "table"},
+		{"", "leg", 4},
+	})
+	for _, row := range []int{3, 4, 7} {
+		require.NoError(t, f.SetRowOutlineLevel(DefaultSheetName, row, 1))
+	}
+
+	tests := []struct {
+		name string
+		opts DecoderOptions
+	}{
+		{name: "outline", opts: DecoderOptions{ChildOutline: true}},
+		{name: "marker", opts: DecoderOptions{ChildMarker: "code"}},
+		{name: "func", opts: DecoderOptions{ChildRow: func(column []string) bool { return column[0] == "" }}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sheet, err := NewDecoder[parent](f, tt.opts)
+			require.NoError(t, err)
+			defer sheet.Close()
+
+			assert.Equal(t, len(want), sheet.Count())
+
+			var got []parent
+			require.NoError(t, sheet.All(&got))
+			assert.Equal(t, want, got)
+		})
+	}
+
+	t.Run("next without decode", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[parent](f, DecoderOptions{ChildMarker: "code"})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		assert.Equal(t, 3, sheet.Skip(5))
+	})
+
+	t.Run("marker not found", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewDecoder[parent](f, DecoderOptions{ChildMarker: "id"})
+		require.Error(t, err)
+	})
+
+	t.Run("field not found", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewDecoder[child](f, DecoderOptions{ChildOutline: true})
+		require.Error(t, err)
+	})
+}
+
+func TestUnmarshal_Ref(t *testing.T) {
+	t.Parallel()
+
+	type order struct {
+		ID       int    `excel:"id"`
+		Customer string `excel:"customer_id,ref=Customers.id"`
+	}
+
+	f := newTestRead(t, [][]any{
+		{"id", "customer_id"},
+		{1, "c1"},
+		{2, "c3"},
+		{3, "c2"},
+	})
+
+	_, err := f.NewSheet("Customers")
+	require.NoError(t, err)
+	for i, row := range [][]any{{"Customer list"}, {"name", "id"}, {"a", "c1"}, {"b", "c2"}} {
+		require.NoError(t, f.SetSheetRow("Customers", fmt.Sprintf("A%d", i+1), &row))
+	}
+
+	sheet, err := NewDecoder[order](f, DecoderOptions{})
+	require.NoError(t, err)
+	defer sheet.Close()
+
+	var got []order
+	for sheet.Next() {
+		var row order
+		if err := sheet.Decode(&row); err != nil {
+			refErr := new(UnmarshalError)
+			require.ErrorAs(t, err, &refErr)
+			assert.Equal(t, []ReferenceError{{Value: "c3", Field: "customer_id", Ref: "Customers.id"}}, refErr.AsReferenceError())
+			assert.Equal(t, 3, refErr.Row)
+			continue
+		}
+		got = append(got, row)
+	}
+	assert.Equal(t, []order{{ID: 1, Customer: "c1"}, {ID: 3, Customer: "c2"}}, got)
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		type v struct {
+			Customer string `excel:"customer_id,ref=Customers.code"`
+		}
+
+		_, err := NewDecoder[v](f, DecoderOptions{})
+		require.Error(t, err)
+	})
+}
+
+func TestDecoder_Key(t *testing.T) {
+	t.Parallel()
+
+	type v struct {
+		SKU   string `excel:"sku,key"`
+		Store int    `excel:"store,key"`
+		Qty   int    `excel:"qty"`
+	}
+
+	f := newTestRead(t, [][]any{
+		{"sku", "store", "qty"},
+		{"a", 1, 10},
+		{"a", 2, 20},
+		{"b", 1, 30},
+		{"a", 1, 40},
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		var got []v
+		err = sheet.All(&got)
+		dupErr := new(UnmarshalError)
+		require.ErrorAs(t, err, &dupErr)
+		assert.Equal(t, []DuplicateKeyError{{
+			Key:        []string{"a", "1"},
+			Sheet:      DefaultSheetName,
+			Row:        5,
+			FirstSheet: DefaultSheetName,
+			FirstRow:   2,
+		}}, dupErr.AsDuplicateKeyError())
+	})
+
+	t.Run("empty key", func(t *testing.T) {
+		t.Parallel()
+
+		r := newTestRead(t, [][]any{
+			{"sku", "store", "qty"},
+			{"a", 1, 10},
+			{nil, nil, 20},
+			{nil, nil, 30},
+		})
+
+		sheet, err := NewDecoder[v](r, DecoderOptions{})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		var got []v
+		require.NoError(t, sheet.All(&got))
+		assert.Len(t, got, 3)
+
+		sheet, err = NewDecoder[v](r, DecoderOptions{})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		for _, err := range sheet.ParallelRows(context.Background(), ParallelOptions{}) {
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("seek", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{DataEndRow: 4})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		var row v
+		require.True(t, sheet.Next())
+		require.NoError(t, sheet.Decode(&row))
+		require.NoError(t, sheet.Seek(2))
+		require.True(t, sheet.Next())
+		require.NoError(t, sheet.Decode(&row))
+	})
+
+	t.Run("parallel", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{})
+		require.NoError(t, err)
+
+		var rows []int
+		for row, err := range sheet.ParallelRows(context.Background(), ParallelOptions{Workers: 2}) {
+			if err != nil {
+				dupErr := new(UnmarshalError)
+				require.ErrorAs(t, err, &dupErr)
+				assert.Equal(t, 2, dupErr.AsDuplicateKeyError()[0].FirstRow)
+				continue
+			}
+			rows = append(rows, row.Index)
+		}
+		assert.Equal(t, []int{2, 3, 4}, rows)
+	})
+}
+
+func TestUnmarshal_CellAttr(t *testing.T) {
+	t.Parallel()
+
+	type v struct {
+		Name       string        `excel:"name"`
+		NameNote   string        `excel:"name,comment"`
+		URL        string        `excel:"url"`
+		URLTarget  string        `excel:"url,hyperlink"`
+		Status     string        `excel:"status"`
+		StatusFill string        `excel:"status,fill,conv=lower"`
+		StatusFont excelize.Font `excel:"status,font"`
+	}
+
+	f := newTestRead(t, [][]any{
+		{"name", "url", "status"},
+		{"a", "site", "ok"},
+		{"b"},
+	})
+	require.NoError(t, f.AddComment(DefaultSheetName, excelize.Comment{Cell: "A2", Text: "check"}))
+	require.NoError(t, f.SetCellHyperLink(DefaultSheetName, "B2", "https://example.com", "External"))
+
+	style, err := f.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"FF0000"}, Pattern: 1},
+		Font: &excelize.Font{Bold: true, Color: "00FF00"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, f.SetCellStyle(DefaultSheetName, "C2", "C2", style))
+
+	sheet, err := NewDecoder[v](f, DecoderOptions{})
+	require.NoError(t, err)
+	defer sheet.Close()
+
+	var got []v
+	require.NoError(t, sheet.All(&got))
+	require.Len(t, got, 2)
+	assert.Equal(t, v{
+		Name:       "a",
+		NameNote:   "check",
+		URL:        "site",
+		URLTarget:  "https://example.com",
+		Status:     "ok",
+		StatusFill: "ff0000",
+		StatusFont: excelize.Font{Bold: true, Color: "00FF00", Family: got[0].StatusFont.Family, Size: got[0].StatusFont.Size},
+	}, got[0])
+	assert.Equal(t, "b", got[1].Name)
+	assert.Empty(t, got[1].NameNote)
+	assert.Empty(t, got[1].StatusFill)
+}
+
+func TestUnmarshal_Formula(t *testing.T) {
+	t.Parallel()
+
+	type v struct {
+		A       int    `excel:"a"`
+		B       int    `excel:"b"`
+		Sum     int    `excel:"sum"`
+		SumText string `excel:"sum,formula"`
+	}
+
+	f := newTestRead(t, [][]any{
+		{"a", "b", "sum"},
+		{1, 2},
+		{3, 0},
+	})
+	require.NoError(t, f.SetCellFormula(DefaultSheetName, "C2", "A2+B2"))
+	require.NoError(t, f.SetCellFormula(DefaultSheetName, "C3", "A3/B3"))
+
+	t.Run("cached", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		var got []v
+		require.NoError(t, sheet.All(&got))
+		assert.Equal(t, []v{{1, 2, 0, "A2+B2"}, {3, 0, 0, "A3/B3"}}, got)
+	})
+
+	t.Run("calc", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{Formula: FormulaCalc})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		require.True(t, sheet.Next())
+		var got v
+		require.NoError(t, sheet.Decode(&got))
+		assert.Equal(t, v{1, 2, 3, "A2+B2"}, got)
+
+		require.True(t, sheet.Next())
+		err = sheet.Decode(&got)
+		unmarshalErr := new(UnmarshalError)
+		require.ErrorAs(t, err, &unmarshalErr)
+
+		formulaErr := unmarshalErr.AsFormulaError()
+		require.Len(t, formulaErr, 1)
+		assert.Equal(t, "C3", formulaErr[0].Cell)
+		assert.Equal(t, "#DIV/0!", formulaErr[0].Value)
+	})
+
+	t.Run("tag", func(t *testing.T) {
+		t.Parallel()
+
+		type v struct {
+			Sum     int    `excel:"sum,calc"`
+			SumText string `excel:"sum,formula"`
+		}
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{Formula: FormulaText})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		require.True(t, sheet.Next())
+		var got v
+		require.NoError(t, sheet.Decode(&got))
+		assert.Equal(t, v{3, "A2+B2"}, got)
+	})
+}
+
+// newTestRead returns the file with rows written from the first cell of the default sheet.
+func newTestRead(t *testing.T, rows [][]any) *Read {
+	t.Helper()
+
+	f := excelize.NewFile()
+	t.Cleanup(func() { f.Close() })
+
+	setTestRows(t, f, rows)
+	return &Read{File: f}
+}
+
+// newTestWrite returns the report written before the encoder.
+func newTestWrite(t *testing.T, rows [][]any) *Write {
+	t.Helper()
+
+	f := NewWrite(WriteFileOptions{})
+	setTestRows(t, f.File, rows)
+	return f
+}
+
+func setTestRows(t *testing.T, f *excelize.File, rows [][]any) {
+	t.Helper()
+
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		require.NoError(t, err)
+		require.NoError(t, f.SetSheetRow(DefaultSheetName, cell, &row))
+	}
 }
 
 func ptrV[T any](v T) *T {
@@ -0,0 +1,228 @@
+package excelstruct
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	workbookXMLPath     = "xl/workbook.xml"
+	workbookRelsXMLPath = "xl/_rels/workbook.xml.rels"
+)
+
+// partSource opens the part of the package from the source of the file,
+// excelize keeps the part larger than Options.UnzipXMLSizeLimit in a temporary file which is not accessible.
+type partSource func(name string) (io.ReadCloser, error)
+
+// zipPart opens the part of the zip archive, the archive is closed with the part.
+func zipPart(r io.ReaderAt, size int64, name string, closer io.Closer) (io.ReadCloser, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	part, err := zr.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{part, closerFunc(func() error { return errors.Join(part.Close(), closer.Close()) })}, nil
+}
+
+// closerFunc is the function implementing io.Closer.
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+// bytesSource returns the source of the file content.
+func bytesSource(b []byte) partSource {
+	return func(name string) (io.ReadCloser, error) {
+		return zipPart(bytes.NewReader(b), int64(len(b)), name, closerFunc(func() error { return nil }))
+	}
+}
+
+// fsSource returns the source of the file in the file system, the file is opened on each call.
+func fsSource(fsys fs.FS, filePath string) partSource {
+	return func(name string) (io.ReadCloser, error) {
+		f, err := fsys.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+
+		r, ok := f.(io.ReaderAt)
+		if !ok {
+			f.Close()
+			return nil, fmt.Errorf("file %q is not io.ReaderAt", filePath)
+		}
+
+		part, err := zipPart(r, info.Size(), name, f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return part, nil
+	}
+}
+
+// sheetDimension returns the used range of the sheet from the dimension reference.
+// The worksheet is not loaded to memory, the raw XML is read up to the reference
+// from the package or from the source if the part is kept in a temporary file.
+func sheetDimension(file *excelize.File, source partSource, sheetName string) (area, bool) {
+	name, ok := sheetXMLPath(file, sheetName)
+	if !ok {
+		return area{}, false
+	}
+
+	var ref string
+	if _, loaded := file.Sheet.Load(name); loaded {
+		// the worksheet is already in memory, so reading is cheap
+		v, err := file.GetSheetDimension(sheetName)
+		if err != nil {
+			return area{}, false
+		}
+		ref = v
+	} else if b, ok := pkgContent(file, name); ok {
+		if ref, ok = dimensionRef(bytes.NewReader(b)); !ok {
+			return area{}, false
+		}
+	} else {
+		if source == nil {
+			return area{}, false
+		}
+
+		part, err := source(name)
+		if err != nil {
+			return area{}, false
+		}
+		defer part.Close()
+
+		if ref, ok = dimensionRef(part); !ok {
+			return area{}, false
+		}
+	}
+
+	a, err := parseRange(ref)
+	if err != nil {
+		return area{}, false
+	}
+	a.sheetName = sheetName
+	return a, true
+}
+
+// dimensionRef returns the reference of the dimension element which is located before the sheet data.
+func dimensionRef(r io.Reader) (string, bool) {
+	dec := xml.NewDecoder(r)
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return "", false
+		}
+
+		el, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch el.Name.Local {
+		case "dimension":
+			return xmlAttr(el, "ref")
+		case "sheetData":
+			return "", false
+		}
+	}
+}
+
+// sheetXMLPath returns the path of the worksheet part in the package by the workbook relationships.
+func sheetXMLPath(file *excelize.File, sheetName string) (string, bool) {
+	workbook, ok := pkgContent(file, workbookXMLPath)
+	if !ok {
+		return "", false
+	}
+
+	var id string
+	dec := xml.NewDecoder(bytes.NewReader(workbook))
+	for id == "" {
+		token, err := dec.Token()
+		if err != nil {
+			return "", false
+		}
+
+		if el, ok := token.(xml.StartElement); ok && el.Name.Local == "sheet" {
+			if name, _ := xmlAttr(el, "name"); name == sheetName {
+				if id, ok = xmlAttr(el, "id"); !ok {
+					return "", false
+				}
+			}
+		}
+	}
+
+	rels, ok := pkgContent(file, workbookRelsXMLPath)
+	if !ok {
+		return "", false
+	}
+
+	dec = xml.NewDecoder(bytes.NewReader(rels))
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return "", false
+		}
+
+		el, ok := token.(xml.StartElement)
+		if !ok || el.Name.Local != "Relationship" {
+			continue
+		}
+
+		if v, _ := xmlAttr(el, "Id"); v != id {
+			continue
+		}
+
+		target, ok := xmlAttr(el, "Target")
+		if !ok {
+			return "", false
+		}
+
+		if strings.HasPrefix(target, "/") {
+			return strings.TrimPrefix(target, "/"), true
+		}
+		return path.Join("xl", target), true
+	}
+}
+
+// pkgContent returns the raw part of the package.
+func pkgContent(file *excelize.File, name string) ([]byte, bool) {
+	content, ok := file.Pkg.Load(name)
+	if !ok {
+		return nil, false
+	}
+
+	b, ok := content.([]byte)
+	return b, ok
+}
+
+// xmlAttr returns the value of the attribute by the local name.
+func xmlAttr(el xml.StartElement, name string) (string, bool) {
+	for _, attr := range el.Attr {
+		if attr.Name.Local == name {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"slices"
 	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -55,6 +56,8 @@ func (e *MarshalerError) Unwrap() error { return e.Err }
 type encOpts struct {
 	stringConv WriteStringConv
 	boolConv   WriteBoolConv
+	nameConv   NameConv
+	conv       []string // converters of the current field
 }
 
 type typeOpts struct {
@@ -72,6 +75,7 @@ type encodeState struct {
 	field                 string
 	row                   int
 	col                   int
+	stream                *streamRow // row buffered for the stream writer, nil if the cells are written to the file
 }
 
 // excelError is an error wrapper type for internal use only.
@@ -173,6 +177,16 @@ func (e *encodeState) reflectValue(v reflect.Value, opts encOpts) {
 
 // writeValue writes value to cell.
 func (e *encodeState) writeValue(value any) {
+	if e.stream != nil {
+		if e.row != e.stream.row {
+			e.error(fmt.Errorf("excelstruct: field %q write to row %d: %w", e.field, e.row, ErrStreamUnsupported))
+		}
+
+		e.stream.set(e.col, value)
+		e.title.incRowData(e.field, e.col)
+		return
+	}
+
 	cell := e.cell()
 	if err := e.file.SetCellValue(e.title.config.sheetName, cell, value); err != nil {
 		e.error(fmt.Errorf("excelstruct: field %q set cell value: %w", e.field, err))
@@ -284,6 +298,7 @@ func unsupportedTypeEncoder(e *encodeState, v reflect.Value, _ encOpts) {
 func invalidValueEncoder(_ *encodeState, _ reflect.Value, _ encOpts) {}
 
 func defaultEncoder(e *encodeState, v reflect.Value, opts encOpts) {
+	value := v.Interface()
 	switch v.Kind() {
 	case reflect.String:
 		if opts.stringConv != nil {
@@ -292,9 +307,7 @@ func defaultEncoder(e *encodeState, v reflect.Value, opts encOpts) {
 				e.error(err)
 				return
 			}
-
-			e.writeValue(nv)
-			return
+			value = nv
 		}
 
 	case reflect.Bool:
@@ -304,12 +317,46 @@ func defaultEncoder(e *encodeState, v reflect.Value, opts encOpts) {
 				e.error(err)
 				return
 			}
+			value = nv
+		}
+	}
 
-			e.writeValue(nv)
-			return
+	if len(opts.conv) > 0 {
+		nv, err := opts.nameConv.write(opts.conv, fmt.Sprint(value))
+		if err != nil {
+			e.error(fmt.Errorf("excelstruct: field %q: %w", e.field, err))
 		}
+		// the bool converted by BoolConv is written as text
+		value = convValue(reflect.TypeOf(value).Kind(), nv)
 	}
-	e.writeValue(v.Interface())
+	e.writeValue(value)
+}
+
+// convValue returns the converted value of the number or bool field as the same kind to keep the type of the cell,
+// the value which is not parsed is written as text.
+func convValue(kind reflect.Kind, v string) any {
+	switch kind {
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseUint(v, 10, 64); err == nil {
+			return n
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	}
+	return v
 }
 
 func timeEncoder(e *encodeState, v reflect.Value, _ encOpts) {
@@ -336,6 +383,9 @@ func (se structEncoder) encode(e *encodeState, v reflect.Value, opts encOpts) {
 FieldLoop:
 	for i := range se.fields.list {
 		f := &se.fields.list[i]
+		if !f.isValue() && f.group == "" {
+			continue
+		}
 
 		// find the nested struct field by following f.index.
 		fv := v
@@ -353,10 +403,18 @@ FieldLoop:
 			continue
 		}
 
+		if f.group != "" {
+			e.group(f, fv, opts)
+			continue
+		}
+
 		if !e.setField(f.name) {
 			continue
 		}
-		f.encoder(e, fv, opts)
+
+		fopts := opts
+		fopts.conv = f.conv
+		f.encoder(e, fv, fopts)
 	}
 }
 
@@ -523,10 +581,22 @@ type field struct {
 	index     []int
 	typ       reflect.Type
 	omitEmpty bool
+	conv      []string
+	meta      string // metadata of the row filled on decode, the field is not encoded
+	attr      string // attribute of the cell decoded instead of the value, the field is not encoded
+	formula   Formula
+	group     string // title pattern of the repeated column groups, the field is a slice of structs
+	ref       string // reference "Sheet.title" of the key column in another sheet
+	key       bool   // part of the key which is unique for rows
 
 	encoder encoderFunc
 }
 
+// isValue reports whether the field holds the value of a cell.
+func (f *field) isValue() bool {
+	return f.meta == "" && f.attr == "" && f.group == ""
+}
+
 // byIndex sorts field by index sequence.
 type byIndex []field
 
@@ -637,6 +707,13 @@ func typeFields(t reflect.Type, opts typeOpts) structFields {
 						index:     index,
 						typ:       ft,
 						omitEmpty: tagOpts.Contains(optOmitempty),
+						conv:      parseConv(tagOpts),
+						meta:      parseMeta(tagOpts),
+						attr:      parseAttr(tagOpts),
+						formula:   parseFormula(tagOpts),
+						group:     parseGroup(tagOpts),
+						ref:       parseRef(tagOpts),
+						key:       tagOpts.Contains(optKey),
 					}
 					field.nameBytes = []byte(field.name)
 					field.equalFold = simpleLetterEqualFold
@@ -663,12 +740,15 @@ func typeFields(t reflect.Type, opts typeOpts) structFields {
 
 	sort.Slice(fields, func(i, j int) bool {
 		x := fields
-		// sort field by name, breaking ties with depth, then
+		// sort field by name and cell attribute, breaking ties with depth, then
 		// breaking ties with "name came from excelstruct tag", then
 		// breaking ties with an index sequence.
 		if x[i].name != x[j].name {
 			return x[i].name < x[j].name
 		}
+		if x[i].attr != x[j].attr {
+			return x[i].attr < x[j].attr
+		}
 		if len(x[i].index) != len(x[j].index) {
 			return len(x[i].index) < len(x[j].index)
 		}
@@ -687,12 +767,12 @@ func typeFields(t reflect.Type, opts typeOpts) structFields {
 	out := fields[:0]
 	for advance, i := 0, 0; i < len(fields); i += advance {
 		// One iteration per name.
-		// Find the sequence of fields with the name of this first field.
+		// Find the sequence of fields with the name and the cell attribute of this first field.
 		fi := fields[i]
 		name := fi.name
 		for advance = 1; i+advance < len(fields); advance++ {
 			fj := fields[i+advance]
-			if fj.name != name {
+			if fj.name != name || fj.attr != fi.attr {
 				break
 			}
 		}
@@ -716,7 +796,9 @@ func typeFields(t reflect.Type, opts typeOpts) structFields {
 	}
 	nameIndex := make(map[string]int, len(fields))
 	for i, field := range fields {
-		nameIndex[field.name] = i
+		if field.isValue() {
+			nameIndex[field.name] = i
+		}
 	}
 	return structFields{fields, nameIndex}
 }
@@ -3,6 +3,7 @@ package excelstruct
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -431,6 +432,541 @@ func TestMarshal_ValueConv(t *testing.T) {
 	})
 }
 
+func TestMarshal_NameConv(t *testing.T) {
+	t.Parallel()
+
+	yn := ValueConv{
+		Write: func(v string) (string, error) {
+			if v == "true" {
+				return "Y", nil
+			}
+			return "N", nil
+		},
+	}
+
+	t.Run("chain", func(t *testing.T) {
+		t.Parallel()
+
+		f, err := WriteFile(WriteFileOptions{})
+		require.NoError(t, err)
+		defer f.Close()
+
+		type v struct {
+			Country string `excel:"country,conv=trim|upper"`
+			Active  bool   `excel:"active,conv=yn"`
+			Count   int    `excel:"count"`
+		}
+
+		sheet, err := NewEncoder[v](f, EncoderOptions{Conv: NameConv{"yn": yn}})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		require.NoError(t, sheet.Encode(&v{Country: " de ", Active: true, Count: 1}))
+		got, err := f.File.GetCols(sheet.enc.title.config.sheetName)
+		require.NoError(t, err)
+
+		want := [][]string{
+			{"country", "DE"},
+			{"active", "Y"},
+			{"count", "1"},
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("number", func(t *testing.T) {
+		t.Parallel()
+
+		f, err := WriteFile(WriteFileOptions{})
+		require.NoError(t, err)
+		defer f.Close()
+
+		type v struct {
+			Int   int     `excel:"int,conv=trim"`
+			Uint  uint    `excel:"uint,conv=trim"`
+			Float float64 `excel:"float,conv=trim"`
+			Text  int     `excel:"text,conv=unit"`
+			Bool  bool    `excel:"bool,conv=trim"`
+		}
+
+		unit := ValueConv{Write: func(v string) (string, error) { return v + " pcs", nil }}
+		sheet, err := NewEncoder[v](f, EncoderOptions{Conv: NameConv{"unit": unit}})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		require.NoError(t, sheet.Encode(&v{Int: 1, Uint: 2, Float: 1.5, Text: 3, Bool: true}))
+		for cell, want := range map[string]excelize.CellType{
+			"A2": excelize.CellTypeUnset, // number
+			"B2": excelize.CellTypeUnset,
+			"C2": excelize.CellTypeUnset,
+			"D2": excelize.CellTypeSharedString,
+			"E2": excelize.CellTypeBool,
+		} {
+			got, err := f.GetCellType(sheet.enc.title.config.sheetName, cell)
+			require.NoError(t, err)
+			assert.Equal(t, want, got, cell)
+		}
+
+		got, err := f.GetCellValue(sheet.enc.title.config.sheetName, "D2")
+		require.NoError(t, err)
+		assert.Equal(t, "3 pcs", got)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		f, err := WriteFile(WriteFileOptions{})
+		require.NoError(t, err)
+		defer f.Close()
+
+		type v struct {
+			Active bool `excel:"active,conv=yn"`
+		}
+
+		_, err = NewEncoder[v](f, EncoderOptions{})
+		assert.EqualError(t, err, `excelstruct: field "active" conv "yn" not found`)
+	})
+}
+
+func TestMarshal_Meta(t *testing.T) {
+	t.Parallel()
+
+	f, err := WriteFile(WriteFileOptions{})
+	require.NoError(t, err)
+	defer f.Close()
+
+	type v struct {
+		Name  string            `excel:"name"`
+		Sheet string            `excel:",sheet"`
+		Row   int               `excel:",row"`
+		Cells map[string]string `excel:",cells"`
+		Note  string            `excel:"name,comment"`
+	}
+
+	sheet, err := NewEncoder[v](f, EncoderOptions{})
+	require.NoError(t, err)
+	defer sheet.Close()
+
+	require.NoError(t, sheet.Encode(&v{Name: "a", Sheet: "Jan", Row: 2, Cells: map[string]string{"name": "A2"}}))
+	got, err := f.File.GetCols(sheet.enc.title.config.sheetName)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "a"}}, got)
+}
+
+func TestMarshal_Group(t *testing.T) {
+	t.Parallel()
+
+	f, err := WriteFile(WriteFileOptions{})
+	require.NoError(t, err)
+	defer f.Close()
+
+	type item struct {
+		Name string `excel:"Name"`
+		Qty  int    `excel:"Qty"`
+	}
+
+	type v struct {
+		Order string `excel:"Order"`
+		Items []item `excel:"items,group=Item{n} "`
+		Total int    `excel:"Total"`
+	}
+
+	sheet, err := NewEncoder[v](f, EncoderOptions{})
+	require.NoError(t, err)
+	defer sheet.Close()
+
+	require.NoError(t, sheet.Encode(&v{Order: "a", Items: []item{{Name: "pen", Qty: 2}}, Total: 2}))
+	require.NoError(t, sheet.Encode(&v{Order: "b", Items: []item{{Name: "cup", Qty: 3}, {Name: "book", Qty: 1}}, Total: 4}))
+	got, err := f.File.GetRows(sheet.enc.title.config.sheetName)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Order", "Item1 Name", "Item1 Qty", "Item2 Name", "Item2 Qty", "Total"},
+		{"a", "pen", "2", "", "", "2"},
+		{"b", "cup", "3", "book", "1", "4"},
+	}, got)
+
+	t.Run("nil item", func(t *testing.T) {
+		t.Parallel()
+
+		f, err := WriteFile(WriteFileOptions{})
+		require.NoError(t, err)
+		defer f.Close()
+
+		type p struct {
+			Order string  `excel:"Order"`
+			Items []*item `excel:"items,group=Item{n} "`
+		}
+
+		sheet, err := NewEncoder[p](f, EncoderOptions{})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		require.NoError(t, sheet.Encode(&p{Order: "a", Items: []*item{{Name: "pen", Qty: 2}, nil, {Name: "cup", Qty: 3}}}))
+		got, err := f.File.GetRows(sheet.enc.title.config.sheetName)
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"Order", "Item1 Name", "Item1 Qty", "Item2 Name", "Item2 Qty", "Item3 Name", "Item3 Qty"},
+			{"a", "pen", "2", "", "", "cup", "3"},
+		}, got)
+	})
+}
+
+func TestMarshal_Children(t *testing.T) {
+	t.Parallel()
+
+	f, err := WriteFile(WriteFileOptions{})
+	require.NoError(t, err)
+	defer f.Close()
+
+	type child struct {
+		Name string `excel:"name"`
+		Qty  int    `excel:"qty"`
+	}
+
+	type parent struct {
+		Name     string  `excel:"name"`
+		Qty      int     `excel:"qty,omitempty"`
+		Children []child `excel:",children"`
+	}
+
+	sheet, err := NewEncoder[parent](f, EncoderOptions{})
+	require.NoError(t, err)
+	defer sheet.Close()
+
+	require.NoError(t, sheet.All([]parent{
+		{Name: "bike", Children: []child{{Name: "wheel", Qty: 2}, {Name: "frame", Qty: 1}}},
+		{Name: "chair"},
+	}))
+
+	sheetName := sheet.enc.title.config.sheetName
+	got, err := f.File.GetRows(sheetName)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "qty"}, {"bike"}, {"wheel", "2"}, {"frame", "1"}, {"chair"}}, got)
+
+	for row, want := range map[int]uint8{2: 0, 3: 1, 4: 1, 5: 0} {
+		level, err := f.File.GetRowOutlineLevel(sheetName, row)
+		require.NoError(t, err)
+		assert.Equal(t, want, level, "row %d", row)
+	}
+}
+
+func TestMarshal_Ref(t *testing.T) {
+	t.Parallel()
+
+	type customer struct {
+		ID string `excel:"id"`
+	}
+
+	type order struct {
+		ID       int    `excel:"id"`
+		Customer string `excel:"customer_id,ref=Customers.id"`
+	}
+
+	tests := []struct {
+		name string
+		conv TitleConv
+	}{
+		{name: "default"},
+		{name: "title conv", conv: strings.ToUpper},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := WriteFile(WriteFileOptions{})
+			require.NoError(t, err)
+			defer f.Close()
+
+			customers, err := NewEncoder[customer](f, EncoderOptions{SheetName: "Customers", TitleConv: tt.conv})
+			require.NoError(t, err)
+			require.NoError(t, customers.All([]customer{{ID: "c1"}, {ID: "c2"}, {ID: "c3"}}))
+			require.NoError(t, customers.Close())
+
+			orders, err := NewEncoder[order](f, EncoderOptions{SheetName: "Orders", TitleConv: tt.conv})
+			require.NoError(t, err)
+			require.NoError(t, orders.All([]order{{ID: 1, Customer: "c1"}, {ID: 2, Customer: "c2"}}))
+			require.NoError(t, orders.Close())
+
+			dv, err := f.File.GetDataValidations("Orders")
+			require.NoError(t, err)
+			require.Len(t, dv, 1)
+			assert.Equal(t, "B2:B3", dv[0].Sqref)
+			assert.Equal(t, "'Customers'!$A$2:$A$4", dv[0].Formula1)
+		})
+	}
+}
+
+func TestEncoder_Append(t *testing.T) {
+	t.Parallel()
+
+	type v struct {
+		Name string `excel:"name"`
+		Qty  int    `excel:"qty"`
+	}
+
+	t.Run("after last row", func(t *testing.T) {
+		t.Parallel()
+
+		f := newTestWrite(t, [][]any{{"qty", "note", "name"}, {1, "first", "pen"}, {2, "", "cup"}})
+		defer f.Close()
+
+		style, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+		require.NoError(t, err)
+		require.NoError(t, f.SetCellStyle(DefaultSheetName, "A3", "C3", style))
+
+		sheet, err := NewEncoder[v](f, EncoderOptions{Append: true})
+		require.NoError(t, err)
+		require.NoError(t, sheet.All([]v{{Name: "book", Qty: 3}, {Name: "box", Qty: 4}}))
+		require.NoError(t, sheet.Close())
+
+		got, err := f.GetRows(DefaultSheetName)
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"qty", "note", "name"},
+			{"1", "first", "pen"},
+			{"2", "", "cup"},
+			{"3", "", "book"},
+			{"4", "", "box"},
+		}, got)
+
+		for _, cell := range []string{"A4", "B4", "C5"} {
+			got, err := f.GetCellStyle(DefaultSheetName, cell)
+			require.NoError(t, err)
+			assert.Equal(t, style, got, cell)
+		}
+
+		header, err := f.GetCellStyle(DefaultSheetName, "A1")
+		require.NoError(t, err)
+		assert.Zero(t, header, "title style is kept")
+	})
+
+	t.Run("title only", func(t *testing.T) {
+		t.Parallel()
+
+		f := newTestWrite(t, [][]any{{"name", "qty"}})
+		defer f.Close()
+
+		sheet, err := NewEncoder[v](f, EncoderOptions{Append: true})
+		require.NoError(t, err)
+		require.NoError(t, sheet.Encode(&v{Name: "pen", Qty: 1}))
+		require.NoError(t, sheet.Close())
+
+		got, err := f.GetRows(DefaultSheetName)
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"name", "qty"}, {"pen", "1"}}, got)
+	})
+
+	t.Run("group", func(t *testing.T) {
+		t.Parallel()
+
+		type item struct {
+			Name string `excel:"Name"`
+		}
+
+		type g struct {
+			Order string `excel:"Order"`
+			Items []item `excel:"items,group=Item{n} "`
+		}
+
+		f := newTestWrite(t, [][]any{{"Order", "Item1 Name", "Item2 Name"}, {"a", "pen", "cup"}})
+		defer f.Close()
+
+		sheet, err := NewEncoder[g](f, EncoderOptions{Append: true})
+		require.NoError(t, err)
+		require.NoError(t, sheet.Encode(&g{Order: "b", Items: []item{{Name: "box"}, {Name: "book"}}}))
+		require.NoError(t, sheet.Close())
+
+		got, err := f.GetRows(DefaultSheetName)
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"Order", "Item1 Name", "Item2 Name"},
+			{"a", "pen", "cup"},
+			{"b", "box", "book"},
+		}, got)
+	})
+
+	t.Run("title mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		f := newTestWrite(t, [][]any{{"name", "amount"}})
+		defer f.Close()
+
+		_, err := NewEncoder[v](f, EncoderOptions{Append: true})
+		assert.ErrorContains(t, err, `title "qty" not found in sheet "Sheet1" row 1`)
+	})
+
+	t.Run("sheet not found", func(t *testing.T) {
+		t.Parallel()
+
+		f := NewWrite(WriteFileOptions{})
+		defer f.Close()
+
+		_, err := NewEncoder[v](f, EncoderOptions{SheetName: "Report", Append: true})
+		assert.ErrorContains(t, err, `sheet "Report" not found`)
+	})
+
+	t.Run("column orientation", func(t *testing.T) {
+		t.Parallel()
+
+		f := newTestWrite(t, [][]any{{"name", "qty"}})
+		defer f.Close()
+
+		_, err := NewEncoder[v](f, EncoderOptions{Append: true, Orientation: OrientationColumn})
+		assert.Error(t, err)
+	})
+}
+
+func TestEncoder_Upsert(t *testing.T) {
+	t.Parallel()
+
+	type v struct {
+		SKU   string  `excel:"sku,key"`
+		Qty   int     `excel:"qty"`
+		Price *string `excel:"price"`
+	}
+
+	t.Run("update and insert", func(t *testing.T) {
+		t.Parallel()
+
+		f := newTestWrite(t, [][]any{
+			{"sku", "note", "qty", "price"},
+			{"a", "by hand", 1, "10"},
+			{"b", "", 2, "20"},
+		})
+		defer f.Close()
+
+		style, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+		require.NoError(t, err)
+		require.NoError(t, f.SetCellStyle(DefaultSheetName, "C2", "C2", style))
+
+		sheet, err := NewEncoder[v](f, EncoderOptions{Upsert: true})
+		require.NoError(t, err)
+		require.NoError(t, sheet.All([]v{{SKU: "a", Qty: 5}, {SKU: "c", Qty: 3, Price: ptrV("30")}, {SKU: "c", Qty: 4}}))
+		require.NoError(t, sheet.Close())
+
+		got, err := f.GetRows(DefaultSheetName)
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"sku", "note", "qty", "price"},
+			{"a", "by hand", "5"},
+			{"b", "", "2", "20"},
+			{"c", "", "4"},
+		}, got)
+
+		inserted, updated := sheet.UpsertCount()
+		assert.Equal(t, 1, inserted)
+		assert.Equal(t, 2, updated)
+
+		got2, err := f.GetCellStyle(DefaultSheetName, "C2")
+		require.NoError(t, err)
+		assert.Equal(t, style, got2, "style of the updated row is kept")
+	})
+
+	t.Run("encoded key", func(t *testing.T) {
+		t.Parallel()
+
+		type k struct {
+			SKU    string  `excel:"sku,key,conv=upper"`
+			Size   float64 `excel:"size,key"`
+			Active bool    `excel:"active,key"`
+			Qty    int     `excel:"qty"`
+		}
+
+		f := newTestWrite(t, [][]any{{"sku", "size", "active", "qty"}, {"ABC", 1e21, true, 1}, {"ABC", 2, true, 2}})
+		defer f.Close()
+
+		sheet, err := NewEncoder[k](f, EncoderOptions{Upsert: true})
+		require.NoError(t, err)
+		require.NoError(t, sheet.All([]k{
+			{SKU: "abc", Size: 1e21, Active: true, Qty: 5},
+			{SKU: "abc", Size: 2.0, Active: true, Qty: 6},
+			{SKU: "abc", Size: 2.0, Active: false, Qty: 7},
+		}))
+		require.NoError(t, sheet.Close())
+
+		inserted, updated := sheet.UpsertCount()
+		assert.Equal(t, 1, inserted)
+		assert.Equal(t, 2, updated)
+
+		got, err := f.GetCols(DefaultSheetName)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"qty", "5", "6", "7"}, got[3])
+	})
+
+	t.Run("style of inserted rows", func(t *testing.T) {
+		t.Parallel()
+
+		f := newTestWrite(t, [][]any{{"sku", "qty", "price"}, {"a", 1}})
+		defer f.Close()
+
+		style, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+		require.NoError(t, err)
+		require.NoError(t, f.SetCellStyle(DefaultSheetName, "A2", "C2", style))
+
+		sheet, err := NewEncoder[v](f, EncoderOptions{Upsert: true})
+		require.NoError(t, err)
+		require.NoError(t, sheet.All([]v{{SKU: "b", Qty: 2}, {SKU: "a", Qty: 3}, {SKU: "c", Qty: 4}}))
+		require.NoError(t, sheet.Close())
+
+		for _, cell := range []string{"A3", "C4"} {
+			got, err := f.GetCellStyle(DefaultSheetName, cell)
+			require.NoError(t, err)
+			assert.Equal(t, style, got, cell)
+		}
+
+		got, err := f.GetCellStyle(DefaultSheetName, "A5")
+		require.NoError(t, err)
+		assert.Zero(t, got, "row after the data")
+	})
+
+	t.Run("empty key", func(t *testing.T) {
+		t.Parallel()
+
+		f := newTestWrite(t, [][]any{{"sku", "qty", "price"}, {"a", 1}})
+		defer f.Close()
+
+		sheet, err := NewEncoder[v](f, EncoderOptions{Upsert: true})
+		require.NoError(t, err)
+		require.NoError(t, sheet.All([]v{{Qty: 5}, {Qty: 6}, {SKU: "a", Qty: 2}}))
+		require.NoError(t, sheet.Close())
+
+		got, err := f.GetRows(DefaultSheetName)
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"sku", "qty", "price"}, {"a", "2"}, {"", "5"}, {"", "6"}}, got)
+
+		inserted, updated := sheet.UpsertCount()
+		assert.Equal(t, 2, inserted)
+		assert.Equal(t, 1, updated)
+	})
+
+	t.Run("without key", func(t *testing.T) {
+		t.Parallel()
+
+		type nokey struct {
+			SKU string `excel:"sku"`
+		}
+
+		f := newTestWrite(t, [][]any{{"sku"}})
+		defer f.Close()
+
+		_, err := NewEncoder[nokey](f, EncoderOptions{Upsert: true})
+		assert.ErrorContains(t, err, `upsert requires the field with the tag option "key"`)
+	})
+
+	t.Run("duplicate key in sheet", func(t *testing.T) {
+		t.Parallel()
+
+		f := newTestWrite(t, [][]any{{"sku", "qty", "price"}, {"a", 1}, {"a", 2}})
+		defer f.Close()
+
+		_, err := NewEncoder[v](f, EncoderOptions{Upsert: true})
+		var dke *DuplicateKeyError
+		require.ErrorAs(t, err, &dke)
+		assert.Equal(t, 3, dke.Row)
+		assert.Equal(t, 2, dke.FirstRow)
+	})
+}
+
 func TestEncoder_Orientation(t *testing.T) {
 	t.Parallel()
 
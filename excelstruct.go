@@ -2,9 +2,17 @@
 package excelstruct
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"iter"
 	"os"
+	"path/filepath"
 	"reflect"
+	"slices"
+	"strings"
 
 	"github.com/xuri/excelize/v2"
 )
@@ -23,23 +31,62 @@ type ValueUnmarshaler interface {
 
 type Read struct {
 	*excelize.File
+	source partSource // source of the parts kept in temporary files, nil if the reader is read once
 }
 
 // OpenFile opens a xlsx file.
 func OpenFile(o OpenFileOptions) (*Read, error) {
-	var opts []excelize.Options
-	if o.Excel != nil {
-		opts = append(opts, *o.Excel)
+	file, err := excelize.OpenFile(o.FilePath, o.excelOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("excelstruct: file: %w", err)
 	}
 
-	file, err := excelize.OpenFile(o.FilePath, opts...)
+	return &Read{File: file, source: fsSource(osFS{}, o.FilePath)}, nil
+}
+
+// osFS is the file system of the operating system which opens the path as is.
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) { return os.Open(name) }
+
+// OpenReader opens a xlsx file from the reader, OpenFileOptions.FilePath is ignored.
+func OpenReader(r io.Reader, o OpenFileOptions) (*Read, error) {
+	file, err := excelize.OpenReader(r, o.excelOptions()...)
 	if err != nil {
-		return nil, fmt.Errorf("excelstruct: file: %w", err)
+		return nil, fmt.Errorf("excelstruct: reader: %w", err)
 	}
 
 	return &Read{File: file}, nil
 }
 
+// OpenBytes opens a xlsx file from the content, OpenFileOptions.FilePath is ignored.
+func OpenBytes(b []byte, o OpenFileOptions) (*Read, error) {
+	r, err := OpenReader(bytes.NewReader(b), o)
+	if err != nil {
+		return nil, err
+	}
+
+	r.source = bytesSource(b)
+	return r, nil
+}
+
+// OpenFS opens a xlsx file by the path OpenFileOptions.FilePath in the file system.
+func OpenFS(fsys fs.FS, o OpenFileOptions) (*Read, error) {
+	f, err := fsys.Open(o.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("excelstruct: file: %w", err)
+	}
+	defer f.Close()
+
+	r, err := OpenReader(f, o)
+	if err != nil {
+		return nil, err
+	}
+
+	r.source = fsSource(fsys, o.FilePath)
+	return r, nil
+}
+
 // Close closes the file.
 func (r *Read) Close() {
 	defer r.File.Close()
@@ -48,55 +95,205 @@ func (r *Read) Close() {
 // Decoder is a workspace for reading data from a file.
 type Decoder[T any] struct {
 	*excelize.File
+	opts      DecoderOptions
+	sheets    []string
+	sheet     int // index of the current sheet
 	sheetName string
-	cursor    *excelize.Rows
+	cursor    *rowCursor
 	rtype     reflect.Type
 	dec       *decodeState
+	attrs     map[string]bool // cell attributes of the struct fields
+	count     int             // number of rows, -1 if not counted yet
+	children  *field          // field of the child rows, nil if the rows are not hierarchical
+	pending   bool            // the current row is the parent row read after the children
+	keys      *keySet         // keys of the decoded rows, nil if the struct has no key fields
+	skipped   []SkippedRow    // rows skipped on the previous sheets
+	source    partSource      // source of the parts kept in temporary files
+	err       error
 }
 
 // NewDecoder creates a decoder with the specified titles and struct.
 func NewDecoder[T any](r *Read, opts DecoderOptions) (*Decoder[T], error) {
 	opts.initDefault()
 
-	// read title
-	cursor, err := r.Rows(opts.SheetName)
+	if err := opts.Conv.check(reflect.TypeFor[T](), opts.StructTag); err != nil {
+		return nil, fmt.Errorf("excelstruct: %w", err)
+	}
+
+	if err := resolveArea(r.File, &opts); err != nil {
+		return nil, fmt.Errorf("excelstruct: %w", err)
+	}
+
+	sheets, err := resolveSheets(r.File, opts)
 	if err != nil {
-		return nil, fmt.Errorf("excel: rows: %w", err)
+		return nil, fmt.Errorf("excelstruct: %w", err)
 	}
 
-	title, err := newTitleFromFile(titleConfig{
-		tag:      opts.StructTag,
-		rowIndex: opts.TitleRowIndex,
-		conv:     opts.TitleConv,
-	}, cursor)
+	refs, err := readRefKeys(r.File, reflect.TypeFor[T](), opts.StructTag, opts.TitleConv)
 	if err != nil {
-		cursor.Close()
-		return nil, fmt.Errorf("excelstruct: title: %w", err)
+		return nil, fmt.Errorf("excelstruct: %w", err)
 	}
 
-	decodeState := &decodeState{
-		opts: decOpts{
-			tag:        opts.StructTag,
-			stringConv: opts.StringConv,
-			boolConv:   opts.BoolConv,
-			timeConv:   opts.TimeConv,
+	var children *field
+	if opts.hasChildren() {
+		if children, err = childrenField(reflect.TypeFor[T](), opts.StructTag); err != nil {
+			return nil, fmt.Errorf("excelstruct: %w", err)
+		}
+
+		if children == nil {
+			return nil, fmt.Errorf("excelstruct: field with tag option %q not found", optChildren)
+		}
+	}
+
+	c := &Decoder[T]{
+		File:     r.File,
+		source:   r.source,
+		opts:     opts,
+		sheets:   sheets,
+		rtype:    reflect.TypeFor[T](),
+		attrs:    fieldAttrs(reflect.TypeFor[T](), opts.StructTag),
+		count:    -1,
+		children: children,
+		keys:     newKeySet(reflect.TypeFor[T](), opts.StructTag),
+		dec: &decodeState{
+			opts: decOpts{
+				tag:        opts.StructTag,
+				stringConv: opts.StringConv,
+				boolConv:   opts.BoolConv,
+				timeConv:   opts.TimeConv,
+				nameConv:   opts.Conv,
+				formula:    opts.Formula,
+				refs:       refs,
+			},
 		},
+	}
+
+	if err := c.openSheet(0); err != nil {
+		return nil, fmt.Errorf("excelstruct: %w", err)
+	}
+	return c, nil
+}
+
+// openSheet opens the cursor of the sheet by index and reads the title.
+func (c *Decoder[T]) openSheet(idx int) error {
+	sheetName := c.sheets[idx]
+	cursor, err := newRowCursor(c.File, sheetName, c.opts)
+	if err != nil {
+		return fmt.Errorf("sheet %q: %w", sheetName, err)
+	}
+
+	title, err := c.readTitle(sheetName, cursor)
+	if err != nil {
+		cursor.Close()
+		return err
+	}
+
+	if c.cursor != nil {
+		for _, row := range c.cursor.skipped {
+			c.skipped = append(c.skipped, SkippedRow{SheetName: c.sheetName, Row: row})
+		}
+		c.cursor.Close()
+	}
+
+	c.sheet = idx
+	c.sheetName = sheetName
+	c.cursor = cursor
+	c.dec.sheet = &sheetState{
+		name:  sheetName,
 		title: title,
-		row:   opts.TitleRowIndex + 1, // position the first row of data
+		file:  c.File,
 	}
 
-	return &Decoder[T]{
-		File:      r.File,
-		sheetName: opts.SheetName,
-		cursor:    cursor,
-		rtype:     reflect.TypeFor[T](),
-		dec:       decodeState,
-	}, nil
+	if c.attrs[optComment] {
+		if c.dec.sheet.comments, err = readComments(c.File, sheetName); err != nil {
+			cursor.Close()
+			return fmt.Errorf("sheet %q: %w", sheetName, err)
+		}
+	}
+	return nil
+}
+
+// readTitle reads the title of the sheet by the cursor.
+func (c *Decoder[T]) readTitle(sheetName string, cursor *rowCursor) (*title, error) {
+	tc := titleConfig{
+		tag:       c.opts.StructTag,
+		rowIndex:  c.opts.TitleRowIndex,
+		sheetName: sheetName,
+		conv:      c.opts.TitleConv,
+	}
+	if c.opts.SkipHiddenColumns {
+		tc.skipColumn = hiddenColumns(c.File, sheetName)
+	}
+
+	title, err := newTitleFromFile(tc, cursor)
+	if err != nil {
+		return nil, fmt.Errorf("sheet %q title: %w", sheetName, err)
+	}
+
+	if c.opts.ChildMarker != "" {
+		if _, ok := title.columnIndex(c.opts.ChildMarker); !ok {
+			return nil, fmt.Errorf("sheet %q child marker title %q not found", sheetName, c.opts.ChildMarker)
+		}
+	}
+	return title, nil
 }
 
-// Next moves the cursor to the next row.
+// Next moves the cursor to the next row, the next sheet is opened when the rows of the current sheet end.
+// The child rows are skipped if the rows are hierarchical.
 func (c *Decoder[T]) Next() bool {
-	return c.cursor.Next()
+	if c.pending {
+		c.pending = false
+		return true
+	}
+
+	for c.next() {
+		if c.children == nil {
+			return true
+		}
+
+		child, err := c.isChild()
+		if err != nil {
+			c.err = fmt.Errorf("excelstruct: %w", err)
+			return false
+		}
+
+		if !child {
+			return true
+		}
+	}
+	return false
+}
+
+// next moves the cursor to the next row of the current or the next sheet.
+func (c *Decoder[T]) next() bool {
+	for !c.cursor.Next() {
+		if c.err != nil || c.cursor.Error() != nil || c.sheet+1 >= len(c.sheets) {
+			return false
+		}
+
+		if err := c.openSheet(c.sheet + 1); err != nil {
+			c.err = fmt.Errorf("excelstruct: %w", err)
+			return false
+		}
+	}
+	return true
+}
+
+// Err returns the error occurred while moving the cursor.
+func (c *Decoder[T]) Err() error {
+	if c.err != nil {
+		return c.err
+	}
+
+	if err := c.cursor.Error(); err != nil {
+		return fmt.Errorf("excelstruct: rows: %w", err)
+	}
+	return nil
+}
+
+// SheetName returns the name of the current sheet.
+func (c *Decoder[T]) SheetName() string {
+	return c.sheetName
 }
 
 // Decode decodes the row to the struct.
@@ -106,45 +303,189 @@ func (c *Decoder[T]) Decode(res *T) error {
 		return fmt.Errorf("excelstruct: get columns: %w", err)
 	}
 
+	c.dec.row = c.cursor.row
 	if err := c.dec.unmarshal(column, res); err != nil {
 		return err
 	}
+
+	if c.keys != nil && !c.keys.empty(column, c.dec.sheet.title) {
+		if err := c.keys.add(c.sheetName, c.cursor.row, reflect.ValueOf(res).Elem()); err != nil {
+			return err
+		}
+	}
+
+	if c.children != nil {
+		return c.decodeChildren(res)
+	}
 	return nil
 }
 
-// Count returns the number of rows.
+// Count returns the number of rows of all sheets, the result is cached.
+// The dimension reference of a sheet is used when the rows are not filtered by content,
+// otherwise the rows are scanned. The worksheet larger than excelize.Options.UnzipXMLSizeLimit
+// is read again from the source, the rows are scanned if the file was opened by OpenReader.
 func (c *Decoder[T]) Count() int {
-	cursor, err := c.File.Rows(c.sheetName)
+	if c.count >= 0 {
+		return c.count
+	}
+
+	count := 0
+	for _, sheetName := range c.sheets {
+		n, ok := c.estimateSheet(sheetName)
+		if !ok {
+			n = c.countSheet(sheetName)
+		}
+		count += n
+	}
+	c.count = count
+	return count
+}
+
+// estimateSheet returns the number of rows of the sheet by the dimension reference.
+func (c *Decoder[T]) estimateSheet(sheetName string) (int, bool) {
+	if c.children != nil || c.opts.SkipHiddenRows || c.opts.BlankRow != BlankRowKeep || c.opts.EndOfData != nil {
+		return 0, false
+	}
+
+	a, ok := sheetDimension(c.File, c.source, sheetName)
+	if !ok || a.endRow <= c.opts.TitleRowIndex {
+		return 0, false
+	}
+
+	endRow := a.endRow
+	if c.opts.DataEndRow > 0 {
+		endRow = min(endRow, c.opts.DataEndRow)
+	}
+
+	count := max(endRow-max(c.opts.DataStartRow, c.opts.TitleRowIndex+1)+1-c.opts.Offset, 0)
+	if c.opts.Limit > 0 {
+		count = min(count, c.opts.Limit)
+	}
+	return count, true
+}
+
+// countSheet returns the number of rows of the sheet by scanning, the child rows are not counted.
+func (c *Decoder[T]) countSheet(sheetName string) int {
+	cursor, err := newRowCursor(c.File, sheetName, c.opts)
 	if err != nil {
 		return 0
 	}
 	defer cursor.Close()
 
-	i := 1
-	// <= because Next() have to put the pointer to the index row
-	for ; i <= c.dec.title.config.rowIndex; i++ {
-		if !cursor.Next() {
+	if c.children == nil {
+		if !cursor.seekTitle() {
 			return 0
 		}
+
+		count := 0
+		for cursor.Next() {
+			count++
+		}
+		return count
+	}
+
+	title, err := c.readTitle(sheetName, cursor)
+	if err != nil {
+		return 0
 	}
 
 	count := 0
 	for cursor.Next() {
-		count++
+		child, err := c.childRow(sheetName, cursor, title)
+		if err != nil {
+			break
+		}
+
+		if !child {
+			count++
+		}
 	}
 	return count
 }
 
+// Seek moves the cursor so the next call of Next returns the data row not less than the given row.
+// The rows between are not decoded, DecoderOptions.Offset is not applied again and DecoderOptions.Limit is counted from the row.
+func (c *Decoder[T]) Seek(row int) error {
+	if err := c.cursor.seek(row); err != nil {
+		return fmt.Errorf("excelstruct: seek %d: %w", row, err)
+	}
+	c.pending = false
+	return nil
+}
+
+// Skip moves the cursor over n data rows without decoding and returns the number of skipped rows.
+func (c *Decoder[T]) Skip(n int) int {
+	skipped := 0
+	for skipped < n && c.Next() {
+		skipped++
+	}
+	return skipped
+}
+
+// SkippedRow is the row which was skipped by the options.
+type SkippedRow struct {
+	SheetName string
+	Row       int
+}
+
+// SkippedRows returns the rows of all read sheets which were skipped by the options.
+func (c *Decoder[T]) SkippedRows() []SkippedRow {
+	rows := slices.Clone(c.skipped)
+	for _, row := range c.cursor.skipped {
+		rows = append(rows, SkippedRow{SheetName: c.sheetName, Row: row})
+	}
+	return rows
+}
+
 // All decodes all rows to the struct.
 func (c *Decoder[T]) All(res *[]T) error {
-	for c.cursor.Next() {
+	for c.Next() {
 		v := reflect.New(c.rtype).Elem().Interface().(T)
 		if err := c.Decode(&v); err != nil {
 			return err
 		}
 		*res = append(*res, v)
 	}
-	return nil
+	return c.Err()
+}
+
+// Row is the decoded row with the row number in Excel.
+type Row[T any] struct {
+	Index int
+	Value T
+}
+
+// Rows returns the iterator over the decoded rows, the error of a row does not stop the iteration.
+// The cursor is closed when the iteration ends.
+func (c *Decoder[T]) Rows() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for row, err := range c.IndexedRows() {
+			if !yield(row.Value, err) {
+				return
+			}
+		}
+	}
+}
+
+// IndexedRows returns the iterator over the decoded rows with the row number.
+// The cursor is closed when the iteration ends.
+func (c *Decoder[T]) IndexedRows() iter.Seq2[Row[T], error] {
+	return func(yield func(Row[T], error) bool) {
+		defer c.Close()
+
+		for c.Next() {
+			var v T
+			row := c.cursor.row // the cursor is moved over the child rows by Decode
+			err := c.Decode(&v)
+			if !yield(Row[T]{Index: row, Value: v}, err) {
+				return
+			}
+		}
+
+		if err := c.Err(); err != nil {
+			yield(Row[T]{}, err)
+		}
+	}
 }
 
 // Close closes the cursor.
@@ -154,7 +495,31 @@ func (c *Decoder[T]) Close() {
 
 type Write struct {
 	*excelize.File
-	config writeConfig
+	config   writeConfig
+	encoders []sheetCloser // encoders in order of creation, closed before writing
+}
+
+// sheetCloser is the encoder of the sheet tracked by Write.
+type sheetCloser struct {
+	sheetName string
+	io.Closer
+}
+
+// track adds the encoder which is closed before writing the file.
+func (w *Write) track(sheetName string, c io.Closer) {
+	w.encoders = append(w.encoders, sheetCloser{sheetName: sheetName, Closer: c})
+}
+
+// closeEncoders closes the encoders in order of creation and returns their errors,
+// the encoder which was closed before returns its error again.
+func (w *Write) closeEncoders() error {
+	var errs []error
+	for _, e := range w.encoders {
+		if err := e.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("excelstruct: close encoder of sheet %q: %w", e.sheetName, err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // WriteFile writes a xlsx file.
@@ -175,10 +540,133 @@ func WriteFile(opts WriteFileOptions) (*Write, error) {
 	}, nil
 }
 
-// Close closes the file.
+// NewWrite creates a xlsx file in memory, WriteFileOptions.FilePath is ignored.
+// The content is taken by WriteTo or Bytes.
+func NewWrite(opts WriteFileOptions) *Write {
+	opts.initDefault()
+
+	return &Write{
+		File:   excelize.NewFile(opts.excelOptions()...),
+		config: writeConfig{structTag: opts.StructTag},
+	}
+}
+
+// WriteTemplate creates a xlsx file in memory from the template, WriteFileOptions.FilePath is ignored.
+func WriteTemplate(r io.Reader, opts WriteFileOptions) (*Write, error) {
+	opts.initDefault()
+
+	file, err := excelize.OpenReader(r, opts.excelOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("excelstruct: template: %w", err)
+	}
+
+	return &Write{
+		File:   file,
+		config: writeConfig{structTag: opts.StructTag},
+	}, nil
+}
+
+// WriteTo closes the encoders and writes the content of the file to w.
+func (w *Write) WriteTo(dst io.Writer) (int64, error) {
+	if err := w.closeEncoders(); err != nil {
+		return 0, err
+	}
+
+	b, err := w.File.WriteToBuffer()
+	if err != nil {
+		return 0, fmt.Errorf("excelstruct: write: %w", err)
+	}
+
+	n, err := b.WriteTo(dst)
+	if err != nil {
+		return n, fmt.Errorf("excelstruct: write: %w", err)
+	}
+	return n, nil
+}
+
+// Bytes closes the encoders and returns the content of the file.
+func (w *Write) Bytes() ([]byte, error) {
+	if err := w.closeEncoders(); err != nil {
+		return nil, err
+	}
+
+	b, err := w.File.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("excelstruct: write: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// Close closes the encoders, saves the file by the path and closes it, the file in memory is closed without saving.
+// The file is not saved if an encoder fails to close.
+// The content is written to a temporary file which replaces the file by the path, so an existing file is never half-overwritten.
 func (w *Write) Close() error {
 	defer w.File.Close()
-	return w.File.SaveAs(w.config.filePath)
+
+	if err := w.closeEncoders(); err != nil {
+		return err
+	}
+
+	if w.config.filePath == "" {
+		return nil
+	}
+
+	if err := saveFile(w.File, w.config.filePath); err != nil {
+		return fmt.Errorf("excelstruct: save file %q: %w", w.config.filePath, err)
+	}
+	return nil
+}
+
+// saveFile writes the file to a temporary file in the same directory and renames it to the path.
+func saveFile(file *excelize.File, path string) (err error) {
+	if _, ok := supportedExt[strings.ToLower(filepath.Ext(path))]; !ok {
+		return excelize.ErrWorkbookFileFormat
+	}
+
+	// keeps the permission of the existing file
+	perm := fs.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	file.Path = path
+	if err := file.Write(tmp); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+
+	if err := tmp.Chmod(perm); err != nil {
+		return fmt.Errorf("chmod: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("sync: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rename: %w", err)
+	}
+	return nil
+}
+
+// supportedExt is the extensions of the workbook which are saved by excelize.
+var supportedExt = map[string]struct{}{
+	".xlam": {}, ".xlsm": {}, ".xlsx": {}, ".xltm": {}, ".xltx": {},
 }
 
 // openExcelFile opens or creates a xlsx file.
@@ -200,51 +688,84 @@ func openExcelFile(path string, o *excelize.Options) (*excelize.File, error) {
 // Encoder is a writing data to a file.
 type Encoder[T any] struct {
 	*excelize.File
-	enc       *encodeState
-	cellStyle *excelize.Style
-	close     bool
-	err       error
-	style     NameStyle
+	enc          *encodeState
+	cellStyle    *excelize.Style
+	children     *field // field of the child rows written with the outline level
+	appendStyles []int  // styles of the last data row by column copied to the appended rows
+	upsert       *upsertState
+	close        bool
+	err          error
+	style        NameStyle
 }
 
 // NewEncoder creates encoder the specified titles and struct.
 func NewEncoder[T any](w *Write, opts EncoderOptions) (*Encoder[T], error) {
 	opts.initDefault()
 
-	if _, err := w.File.NewSheet(opts.SheetName); err != nil {
-		return nil, fmt.Errorf("excelstruct: new sheet %q: %w", opts.SheetName, err)
+	if err := opts.Conv.check(reflect.TypeFor[T](), w.config.structTag); err != nil {
+		return nil, fmt.Errorf("excelstruct: %w", err)
 	}
 
-	title, err := newTitleFromStruct[T](titleConfig{
-		tag:               w.config.structTag,
-		rowIndex:          opts.TitleRowIndex,
-		sheetName:         opts.SheetName,
-		name:              opts.TitleName,
-		conv:              opts.TitleConv,
-		maxWidth:          opts.TitleMaxWidth,
-		scaleAutoWidth:    opts.TitleScaleAutoWidth,
-		dataValidation:    opts.DataValidation,
-		validationOverRow: opts.ValidationOverRow,
-		orient:            opts.Orientation,
-		numFmt:            opts.CellNumFmt,
-		titleNumFmt:       opts.TitleNumFmt,
-		titleStyle:        opts.TitleStyle,
-	}, w.File)
+	var (
+		title        *title
+		appendStyles []int
+		upsert       *upsertState
+		err          error
+	)
+	if opts.Append {
+		if opts.Orientation != OrientationRow {
+			return nil, fmt.Errorf("excelstruct: append requires orientation %q", OrientationRow)
+		}
+
+		title, appendStyles, err = appendTitle[T](w.File, encoderTitleConfig(w, opts))
+		if err != nil {
+			return nil, fmt.Errorf("excelstruct: append: %w", err)
+		}
+
+		if opts.Upsert {
+			if upsert, err = newUpsertState(title, keyFields(reflect.TypeFor[T](), w.config.structTag)); err != nil {
+				return nil, fmt.Errorf("excelstruct: upsert: %w", err)
+			}
+		}
+	} else {
+		if _, err := w.File.NewSheet(opts.SheetName); err != nil {
+			return nil, fmt.Errorf("excelstruct: new sheet %q: %w", opts.SheetName, err)
+		}
+
+		title, err = newTitleFromStruct[T](encoderTitleConfig(w, opts), w.File)
+		if err != nil {
+			return nil, fmt.Errorf("excelstruct: init title: %w", err)
+		}
+
+		// write title
+		if err := title.write(); err != nil {
+			return nil, fmt.Errorf("excelstruct: write title: %w", err)
+		}
+	}
+
+	children, err := childrenField(reflect.TypeFor[T](), w.config.structTag)
 	if err != nil {
-		return nil, fmt.Errorf("excelstruct: init title: %w", err)
+		return nil, fmt.Errorf("excelstruct: %w", err)
 	}
 
-	// write title
-	if err := title.write(); err != nil {
-		return nil, fmt.Errorf("excelstruct: write title: %w", err)
+	if children != nil && upsert != nil {
+		return nil, fmt.Errorf("excelstruct: upsert: field %q children is not supported", children.name)
 	}
 
-	return &Encoder[T]{
+	if children != nil {
+		// the parent row is above the child rows
+		if err := w.File.SetSheetProps(opts.SheetName, &excelize.SheetPropsOptions{OutlineSummaryBelow: new(bool)}); err != nil {
+			return nil, fmt.Errorf("excelstruct: sheet %q props: %w", opts.SheetName, err)
+		}
+	}
+
+	e := &Encoder[T]{
 		File: w.File,
 		enc: &encodeState{
 			encOpts: encOpts{
 				stringConv: opts.StringConv,
 				boolConv:   opts.BoolConv,
+				nameConv:   opts.Conv,
 			},
 			typeOpts: typeOpts{
 				structTag: w.config.structTag,
@@ -253,16 +774,51 @@ func NewEncoder[T any](w *Write, opts EncoderOptions) (*Encoder[T], error) {
 			disallowUnknownFields: opts.DisallowUnknownFields,
 			title:                 title,
 			file:                  w.File,
-			row:                   opts.TitleRowIndex + 1, // position the first row of data
+			row:                   title.firstDataRow(), // position the first row of data
 		},
-		cellStyle: opts.CellStyle,
-		style:     opts.Style,
-	}, nil
+		cellStyle:    opts.CellStyle,
+		children:     children,
+		appendStyles: appendStyles,
+		upsert:       upsert,
+		style:        opts.Style,
+	}
+	w.track(opts.SheetName, e)
+	return e, nil
 }
 
-// Encode writes v to file.
+// encoderTitleConfig returns the config of the title written by the encoder.
+func encoderTitleConfig(w *Write, opts EncoderOptions) titleConfig {
+	return titleConfig{
+		tag:               w.config.structTag,
+		rowIndex:          opts.TitleRowIndex,
+		sheetName:         opts.SheetName,
+		name:              opts.TitleName,
+		conv:              opts.TitleConv,
+		maxWidth:          opts.TitleMaxWidth,
+		scaleAutoWidth:    opts.TitleScaleAutoWidth,
+		dataValidation:    opts.DataValidation,
+		validationOverRow: opts.ValidationOverRow,
+		orient:            opts.Orientation,
+		numFmt:            opts.CellNumFmt,
+		titleNumFmt:       opts.TitleNumFmt,
+		titleStyle:        opts.TitleStyle,
+	}
+}
+
+// Encode writes v to file, in the upsert mode v updates the row with the same key.
 func (e *Encoder[T]) Encode(v *T) error {
-	return e.enc.marshal(v)
+	if e.upsert != nil {
+		return e.encodeUpsert(v)
+	}
+
+	if err := e.enc.marshal(v); err != nil {
+		return err
+	}
+
+	if e.children != nil {
+		return e.encodeChildren(v)
+	}
+	return nil
 }
 
 // All writes all values to file.
@@ -297,41 +853,39 @@ func (e *Encoder[T]) Close() (err error) {
 		return fmt.Errorf("write data validation: %w", err)
 	}
 
+	if err := e.enc.title.writeRefValidation(refFields(reflect.TypeFor[T](), e.enc.typeOpts.structTag)); err != nil {
+		return fmt.Errorf("write ref data validation: %w", err)
+	}
+
 	if err := e.enc.title.writeWidth(); err != nil {
 		return fmt.Errorf("title width: %w", err)
 	}
 
-	if err := e.applyCellStyle(); err != nil {
+	if e.enc.title.config.appendRow > 0 {
+		if err := e.copyRowStyle(); err != nil {
+			return fmt.Errorf("append style: %w", err)
+		}
+		return nil
+	}
+
+	if err := e.applyCellStyle(e.enc.title.config.rowIndex); err != nil {
 		return fmt.Errorf("global style: %w", err)
 	}
 	return nil
 }
 
-func (e *Encoder[T]) applyCellStyle() error {
+// applyCellStyle applies the style of the titles to the rows from the row to the last data row.
+func (e *Encoder[T]) applyCellStyle(fromRow int) error {
 	// aligns by max row data
-	nextRow := e.enc.title.config.rowIndex
-	for _, n := range e.enc.title.name {
-		for _, v := range n.RowData {
-			if v > nextRow {
-				nextRow = v
-			}
-		}
+	nextRow := e.enc.title.maxRowData()
+	if nextRow < fromRow {
+		return nil
 	}
 
 	for _, t := range e.enc.title.name {
-		numFmtID := e.enc.title.numFmt[t.Name]
-		var style excelize.Style
-		if e.cellStyle != nil {
-			style = *e.cellStyle
-		}
-		style.NumFmt = numFmtID
-		if err := e.initStyle(t.Name, &style); err != nil {
-			return fmt.Errorf("init style: %w", err)
-		}
-
-		styleID, err := e.File.NewStyle(&style)
+		styleID, err := newTitleStyle(e.enc.title, t.Name, e.cellStyle, e.style)
 		if err != nil {
-			return fmt.Errorf("new style: %w", err)
+			return err
 		}
 
 		// max column a title
@@ -342,7 +896,7 @@ func (e *Encoder[T]) applyCellStyle() error {
 			}
 		}
 
-		h, err := excelize.CoordinatesToCellName(t.Column[0], e.enc.title.config.rowIndex)
+		h, err := excelize.CoordinatesToCellName(t.Column[0], fromRow)
 		if err != nil {
 			return fmt.Errorf("coordinates to cell name: %w", err)
 		}
@@ -359,14 +913,32 @@ func (e *Encoder[T]) applyCellStyle() error {
 	return nil
 }
 
+// newTitleStyle creates the style of the title column by the number format, the cell style and the style of the title.
+func newTitleStyle(t *title, name string, cellStyle *excelize.Style, nameStyle NameStyle) (int, error) {
+	var style excelize.Style
+	if cellStyle != nil {
+		style = *cellStyle
+	}
+	style.NumFmt = t.numFmt[name]
+	if err := initStyle(t, nameStyle, name, &style); err != nil {
+		return 0, fmt.Errorf("init style: %w", err)
+	}
+
+	styleID, err := t.file.NewStyle(&style)
+	if err != nil {
+		return 0, fmt.Errorf("new style: %w", err)
+	}
+	return styleID, nil
+}
+
 // initStyle initializes the style by title.
-func (e *Encoder[T]) initStyle(title string, style *excelize.Style) error {
-	styleName, ok := e.enc.title.config.titleStyle[title]
+func initStyle(t *title, nameStyle NameStyle, name string, style *excelize.Style) error {
+	styleName, ok := t.config.titleStyle[name]
 	if !ok {
 		return nil
 	}
 
-	s, ok := e.style[styleName]
+	s, ok := nameStyle[styleName]
 	if !ok {
 		return fmt.Errorf("style %q not found", styleName)
 	}
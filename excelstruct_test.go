@@ -1 +1,303 @@
 package excelstruct
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+
+	type v struct {
+		Int    int    `excel:"int"`
+		String string `excel:"string"`
+	}
+
+	decode := func(t *testing.T, f *Read) []v {
+		t.Helper()
+		defer f.Close()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{})
+		require.NoError(t, err)
+		defer sheet.Close()
+
+		var got []v
+		require.NoError(t, sheet.All(&got))
+		return got
+	}
+
+	want := []v{{Int: 1, String: "hello"}}
+
+	t.Run("reader", func(t *testing.T) {
+		t.Parallel()
+
+		file, err := os.Open("testdata/type.xlsx")
+		require.NoError(t, err)
+		defer file.Close()
+
+		f, err := OpenReader(file, OpenFileOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, want, decode(t, f))
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := os.ReadFile("testdata/type.xlsx")
+		require.NoError(t, err)
+
+		f, err := OpenBytes(b, OpenFileOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, want, decode(t, f))
+	})
+
+	t.Run("fs", func(t *testing.T) {
+		t.Parallel()
+
+		f, err := OpenFS(os.DirFS("testdata"), OpenFileOptions{FilePath: "type.xlsx"})
+		require.NoError(t, err)
+		assert.Equal(t, want, decode(t, f))
+	})
+
+	t.Run("fs not found", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := OpenFS(os.DirFS("testdata"), OpenFileOptions{FilePath: "unknown.xlsx"})
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	type v struct {
+		Int    int    `excel:"int"`
+		String string `excel:"string"`
+	}
+
+	want := []v{{Int: 1, String: "hello"}, {Int: 2, String: "world"}}
+
+	encode := func(t *testing.T, w *Write) {
+		t.Helper()
+
+		enc, err := NewEncoder[v](w, EncoderOptions{})
+		require.NoError(t, err)
+		require.NoError(t, enc.All(want))
+		require.NoError(t, enc.Close())
+	}
+
+	decode := func(t *testing.T, b []byte) []v {
+		t.Helper()
+
+		f, err := OpenBytes(b, OpenFileOptions{})
+		require.NoError(t, err)
+		defer f.Close()
+
+		dec, err := NewDecoder[v](f, DecoderOptions{})
+		require.NoError(t, err)
+		defer dec.Close()
+
+		var got []v
+		require.NoError(t, dec.All(&got))
+		return got
+	}
+
+	t.Run("bytes", func(t *testing.T) {
+		t.Parallel()
+
+		w := NewWrite(WriteFileOptions{})
+		defer w.Close()
+		encode(t, w)
+
+		b, err := w.Bytes()
+		require.NoError(t, err)
+		assert.Equal(t, want, decode(t, b))
+	})
+
+	t.Run("write to", func(t *testing.T) {
+		t.Parallel()
+
+		w := NewWrite(WriteFileOptions{})
+		defer w.Close()
+		encode(t, w)
+
+		var buf bytes.Buffer
+		n, err := w.WriteTo(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, int64(buf.Len()), n)
+		assert.Equal(t, want, decode(t, buf.Bytes()))
+	})
+
+	t.Run("template", func(t *testing.T) {
+		t.Parallel()
+
+		file, err := os.Open("testdata/type.xlsx")
+		require.NoError(t, err)
+		defer file.Close()
+
+		w, err := WriteTemplate(file, WriteFileOptions{})
+		require.NoError(t, err)
+		defer w.Close()
+
+		_, err = w.NewSheet("Template")
+		require.NoError(t, err)
+		require.NoError(t, w.SetCellStr("Template", "A1", "kept"))
+
+		b, err := w.Bytes()
+		require.NoError(t, err)
+
+		f, err := OpenBytes(b, OpenFileOptions{})
+		require.NoError(t, err)
+		defer f.Close()
+
+		got, err := f.GetCellValue("Template", "A1")
+		require.NoError(t, err)
+		assert.Equal(t, "kept", got)
+		assert.Contains(t, f.GetSheetList(), DefaultSheetName)
+	})
+
+	t.Run("template invalid", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := WriteTemplate(bytes.NewReader([]byte("invalid")), WriteFileOptions{})
+		assert.Error(t, err)
+	})
+}
+
+func TestWrite_Close(t *testing.T) {
+	t.Parallel()
+
+	type v struct {
+		Int    int    `excel:"int"`
+		String string `excel:"string"`
+	}
+
+	t.Run("closes encoders", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "book.xlsx")
+
+		w, err := WriteFile(WriteFileOptions{FilePath: path})
+		require.NoError(t, err)
+
+		enc, err := NewEncoder[v](w, EncoderOptions{
+			DataValidation: func(title string) (*excelize.DataValidation, error) {
+				if title != "int" {
+					return nil, nil
+				}
+
+				dv := excelize.NewDataValidation(true)
+				return dv, dv.SetDropList([]string{"1", "2"})
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, enc.Encode(&v{Int: 1, String: "hello"}))
+
+		stream, err := NewStreamEncoder[v](w, EncoderOptions{SheetName: "Stream"})
+		require.NoError(t, err)
+		require.NoError(t, stream.Encode(&v{Int: 2, String: "world"}))
+		require.NoError(t, w.Close())
+
+		f, err := OpenFile(OpenFileOptions{FilePath: path})
+		require.NoError(t, err)
+		defer f.Close()
+
+		dv, err := f.GetDataValidations(DefaultSheetName)
+		require.NoError(t, err)
+		assert.Len(t, dv, 1)
+
+		got, err := f.GetCellValue("Stream", "B2")
+		require.NoError(t, err)
+		assert.Equal(t, "world", got)
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "temporary file is removed")
+	})
+
+	t.Run("encoder error", func(t *testing.T) {
+		t.Parallel()
+
+		type ref struct {
+			Customer string `excel:"customer_id,ref=Customers.id"`
+		}
+
+		path := filepath.Join(t.TempDir(), "book.xlsx")
+		prev, err := WriteFile(WriteFileOptions{FilePath: path})
+		require.NoError(t, err)
+		require.NoError(t, prev.SetCellStr(DefaultSheetName, "A1", "previous"))
+		require.NoError(t, prev.Close())
+
+		w, err := WriteFile(WriteFileOptions{FilePath: path})
+		require.NoError(t, err)
+
+		enc, err := NewEncoder[ref](w, EncoderOptions{SheetName: "Orders"})
+		require.NoError(t, err)
+		require.NoError(t, enc.Encode(&ref{Customer: "c1"}))
+
+		assert.ErrorContains(t, w.Close(), `close encoder of sheet "Orders"`)
+
+		f, err := OpenFile(OpenFileOptions{FilePath: path})
+		require.NoError(t, err)
+		defer f.Close()
+
+		assert.NotContains(t, f.GetSheetList(), "Orders", "existing file is not overwritten")
+	})
+
+	t.Run("replaces existing file", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "book.xlsx")
+		for _, s := range []string{"first", "second"} {
+			w, err := WriteFile(WriteFileOptions{FilePath: path})
+			require.NoError(t, err)
+			require.NoError(t, w.SetCellStr(DefaultSheetName, "A1", s))
+			require.NoError(t, w.Close())
+		}
+
+		f, err := OpenFile(OpenFileOptions{FilePath: path})
+		require.NoError(t, err)
+		defer f.Close()
+
+		got, err := f.GetCellValue(DefaultSheetName, "A1")
+		require.NoError(t, err)
+		assert.Equal(t, "second", got)
+	})
+
+	t.Run("unsupported extension", func(t *testing.T) {
+		t.Parallel()
+
+		w, err := WriteFile(WriteFileOptions{FilePath: filepath.Join(t.TempDir(), "book.txt")})
+		require.NoError(t, err)
+		assert.ErrorIs(t, w.Close(), excelize.ErrWorkbookFileFormat)
+	})
+}
+
+func TestWrite_CloseRelativePath(t *testing.T) {
+	// changes the working directory, so the test is not parallel
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { require.NoError(t, os.Chdir(wd)) })
+
+	// the temporary file must not be created in the temporary directory of the system
+	t.Setenv("TMPDIR", filepath.Join(dir, "unknown"))
+
+	w, err := WriteFile(WriteFileOptions{FilePath: "report.xlsx"})
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "report.xlsx", entries[0].Name())
+}
@@ -0,0 +1,370 @@
+package excelstruct
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// DecodeForm reads the form sheet into the struct, the labels are in the anchor column and the values are in the next one.
+// A label without value starts the section of the field with the tag option "section", a blank row ends the section.
+// The cell attributes and the row metadata are not filled, the cached values of formulas are read.
+func DecodeForm[T any](r *Read, v *T, opts DecodeFormOptions) error {
+	opts.initDefault()
+
+	if v == nil {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("excelstruct: type %q not support", t.String())
+	}
+
+	if err := opts.Conv.check(t, opts.StructTag); err != nil {
+		return fmt.Errorf("excelstruct: %w", err)
+	}
+
+	labelCol, anchorRow, err := excelize.CellNameToCoordinates(opts.Anchor)
+	if err != nil {
+		return fmt.Errorf("excelstruct: anchor %q: %w", opts.Anchor, err)
+	}
+
+	refs, err := readRefKeys(r.File, t, opts.StructTag, opts.TitleConv)
+	if err != nil {
+		return fmt.Errorf("excelstruct: %w", err)
+	}
+
+	sections := formSections(t, opts.StructTag)
+	values, err := readForm(r.File, opts, labelCol, anchorRow, sections)
+	if err != nil {
+		return fmt.Errorf("excelstruct: %w", err)
+	}
+
+	d := &decodeState{
+		opts: decOpts{
+			tag:        opts.StructTag,
+			stringConv: opts.StringConv,
+			boolConv:   opts.BoolConv,
+			timeConv:   opts.TimeConv,
+			nameConv:   opts.Conv,
+			formula:    FormulaCached,
+			refs:       refs,
+			form:       true,
+		},
+	}
+
+	unmarshalError := &UnmarshalError{}
+	decode := func(values map[string]string, v reflect.Value) {
+		title, data := formRow(values)
+		d.sheet = &sheetState{name: opts.SheetName, title: title, file: r.File}
+		if err := d.object(data, v); err != nil {
+			if ue, ok := err.(*UnmarshalError); ok {
+				unmarshalError.Err = append(unmarshalError.Err, ue.Err...)
+				return
+			}
+			unmarshalError.saveError(err)
+		}
+	}
+
+	root := reflect.ValueOf(v).Elem()
+	decode(values[""], root)
+	for _, f := range sections {
+		section, ok := values[f.name]
+		if !ok {
+			continue
+		}
+
+		subv, err := fieldByIndex(root, f.index)
+		if err != nil {
+			unmarshalError.saveError(err)
+			continue
+		}
+
+		if _, subv = indirect(subv); subv.Kind() != reflect.Struct {
+			unmarshalError.saveError(&UnmarshalTypeError{Value: "section", Type: subv.Type(), Field: f.name})
+			continue
+		}
+		decode(section, subv)
+	}
+
+	if len(unmarshalError.Err) > 0 {
+		return unmarshalError
+	}
+	return nil
+}
+
+// readForm returns the values by the labels of the sections, the values of the top level are in the empty section.
+func readForm(file *excelize.File, opts DecodeFormOptions, labelCol, anchorRow int, sections []field) (map[string]map[string]string, error) {
+	rows, err := file.Rows(opts.SheetName)
+	if err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+	defer rows.Close()
+
+	cell := func(column []string, col int) string {
+		if col > len(column) {
+			return ""
+		}
+		return strings.TrimSpace(column[col-1])
+	}
+
+	values := map[string]map[string]string{"": {}}
+	section := ""
+	for row := 1; rows.Next(); row++ {
+		if row < anchorRow {
+			continue
+		}
+
+		column, err := rows.Columns()
+		if err != nil {
+			return nil, fmt.Errorf("row %d columns: %w", row, err)
+		}
+
+		label, value := cell(column, labelCol), cell(column, labelCol+1)
+		if isEmptyString(label) && isEmptyString(value) {
+			section = ""
+			continue
+		}
+
+		label = opts.TitleConv(label)
+		if section == "" && isEmptyString(value) && slices.ContainsFunc(sections, func(f field) bool { return f.name == label }) {
+			section = label
+			values[section] = make(map[string]string)
+			continue
+		}
+
+		// the first label wins
+		if _, ok := values[section][label]; !ok {
+			values[section][label] = value
+		}
+	}
+
+	if err := rows.Error(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+	return values, nil
+}
+
+// formRow returns the title and the row of the form values to decode them as a table row.
+func formRow(values map[string]string) (*title, []string) {
+	labels := make([]string, 0, len(values))
+	for k := range values {
+		labels = append(labels, k)
+	}
+	slices.Sort(labels)
+
+	t := &title{
+		name: make([]titleName, 0, len(labels)),
+		idx:  make(map[string]int, len(labels)),
+	}
+	data := make([]string, 0, len(labels))
+	for i, label := range labels {
+		t.name = append(t.name, titleName{Name: label, Column: []int{i + 1}})
+		t.idx[label] = i
+		data = append(data, values[label])
+	}
+	return t, data
+}
+
+// formSections returns the fields with the tag option "section".
+func formSections(t reflect.Type, tag string) []field {
+	var sections []field
+	for _, f := range cachedTypeFields(t, typeOpts{structTag: tag}).list {
+		if f.meta == optSection {
+			sections = append(sections, f)
+		}
+	}
+	return sections
+}
+
+// EncodeForm writes the struct as the form sheet, the labels are in the anchor column and the values are in the next one.
+// The fields with the tag option "section" are written after the top level fields,
+// each section is separated by a blank row and starts with the label of the section.
+func EncodeForm[T any](w *Write, v *T, opts EncodeFormOptions) error {
+	opts.initDefault()
+
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("excelstruct: type %q not support", t.String())
+	}
+
+	if err := opts.Conv.check(t, w.config.structTag); err != nil {
+		return fmt.Errorf("excelstruct: %w", err)
+	}
+
+	labelCol, anchorRow, err := excelize.CellNameToCoordinates(opts.Anchor)
+	if err != nil {
+		return fmt.Errorf("excelstruct: anchor %q: %w", opts.Anchor, err)
+	}
+
+	if _, err := w.File.NewSheet(opts.SheetName); err != nil {
+		return fmt.Errorf("excelstruct: new sheet %q: %w", opts.SheetName, err)
+	}
+
+	fw := &formWriter{
+		enc: &encodeState{
+			encOpts: encOpts{
+				stringConv: opts.StringConv,
+				boolConv:   opts.BoolConv,
+				nameConv:   opts.Conv,
+			},
+			typeOpts: typeOpts{structTag: w.config.structTag},
+			orient:   OrientationRow,
+			file:     w.File,
+		},
+		sheetName: opts.SheetName,
+		conv:      opts.TitleConv,
+		numFmt:    opts.CellNumFmt,
+		col:       labelCol,
+		row:       anchorRow,
+	}
+
+	var rv reflect.Value
+	if v != nil {
+		rv = reflect.ValueOf(v).Elem()
+	}
+	return fw.marshal(t, rv)
+}
+
+// formWriter writes the labels and the values of the form.
+type formWriter struct {
+	enc       *encodeState
+	sheetName string
+	conv      TitleConv
+	numFmt    map[excelize.CellType]int
+	dateStyle int // style of the time values, 0 if not created yet
+	col       int // column of the labels
+	row       int // row of the next label
+}
+
+func (w *formWriter) marshal(t reflect.Type, v reflect.Value) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if je, ok := r.(excelError); ok {
+				err = je.error
+			} else {
+				panic(r)
+			}
+		}
+	}()
+
+	w.fields(t, v)
+	for _, f := range formSections(t, w.enc.typeOpts.structTag) {
+		st := f.typ
+		if st.Kind() == reflect.Pointer {
+			st = st.Elem()
+		}
+
+		if st.Kind() != reflect.Struct {
+			w.enc.error(fmt.Errorf("excelstruct: field %q section requires struct", f.name))
+		}
+
+		w.row++ // blank row
+		w.label(f.name)
+		w.row++
+		w.fields(st, fieldValue(v, f.index))
+	}
+	return nil
+}
+
+// fields writes the value fields of the struct, the labels are written if the value is invalid.
+func (w *formWriter) fields(t reflect.Type, v reflect.Value) {
+	for _, f := range cachedTypeFields(t, w.enc.typeOpts).list {
+		if !f.isValue() {
+			continue
+		}
+
+		switch f.typ.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			if f.typ.Elem().Kind() != reflect.Uint8 {
+				w.enc.error(fmt.Errorf("excelstruct: field %q of type %s is not supported in form", f.name, f.typ))
+			}
+		}
+
+		w.label(f.name)
+		fv := fieldValue(v, f.index)
+		if fv.IsValid() && !(f.omitEmpty && isEmptyValue(fv)) {
+			w.enc.title = w.valueTitle(f.name)
+			w.enc.row = w.row
+			w.enc.setField(f.name)
+
+			opts := w.enc.encOpts
+			opts.conv = f.conv
+			f.encoder(w.enc, fv, opts)
+
+			if f.typ == timeType {
+				w.setDateStyle()
+			}
+		}
+		w.row++
+	}
+}
+
+// setDateStyle sets the number format of the date to the value of the current row.
+func (w *formWriter) setDateStyle() {
+	if w.dateStyle == 0 {
+		style, err := w.enc.file.NewStyle(&excelize.Style{NumFmt: w.numFmt[excelize.CellTypeDate]})
+		if err != nil {
+			w.enc.error(fmt.Errorf("excelstruct: date style: %w", err))
+		}
+		w.dateStyle = style
+	}
+
+	cell := w.enc.cell()
+	if err := w.enc.file.SetCellStyle(w.sheetName, cell, cell, w.dateStyle); err != nil {
+		w.enc.error(fmt.Errorf("excelstruct: cell %q style: %w", cell, err))
+	}
+}
+
+// label writes the label of the current row.
+func (w *formWriter) label(name string) {
+	cell, err := excelize.CoordinatesToCellName(w.col, w.row)
+	if err != nil {
+		w.enc.error(fmt.Errorf("excelstruct: label %q: %w", name, err))
+	}
+
+	if err := w.enc.file.SetCellStr(w.sheetName, cell, w.conv(name)); err != nil {
+		w.enc.error(fmt.Errorf("excelstruct: label %q cell %q: %w", name, cell, err))
+	}
+}
+
+// valueTitle returns the title of one field whose value is written next to the label.
+func (w *formWriter) valueTitle(name string) *title {
+	return &title{
+		file: w.enc.file,
+		name: []titleName{{
+			Name:    name,
+			Column:  []int{w.col + 1},
+			Width:   map[int]float64{},
+			RowData: map[int]int{0: w.row},
+		}},
+		idx:    map[string]int{name: 0},
+		config: titleConfig{sheetName: w.sheetName, conv: w.conv, rowIndex: w.row},
+	}
+}
+
+// fieldValue returns the nested field of the struct, the value is invalid if a pointer is nil.
+func fieldValue(v reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		if !v.IsValid() {
+			return v
+		}
+
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+
+	if v.IsValid() && v.Kind() == reflect.Pointer && v.IsNil() {
+		return reflect.Value{}
+	}
+	return v
+}
@@ -0,0 +1,110 @@
+package excelstruct
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testFormBilling struct {
+	Account string `excel:"Account"`
+	Bank    string `excel:"Bank,conv=upper"`
+}
+
+type testForm struct {
+	Customer string           `excel:"Customer"`
+	Date     time.Time        `excel:"Date"`
+	Amount   float64          `excel:"Amount"`
+	Paid     bool             `excel:"Paid"`
+	Note     *string          `excel:"Note"`
+	Billing  *testFormBilling `excel:"Billing,section"`
+}
+
+func TestForm(t *testing.T) {
+	t.Parallel()
+
+	t.Run("encode decode", func(t *testing.T) {
+		t.Parallel()
+
+		f, err := WriteFile(WriteFileOptions{})
+		require.NoError(t, err)
+		defer f.Close()
+
+		want := testForm{
+			Customer: "ACME",
+			Date:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			Amount:   10.5,
+			Paid:     true,
+			Billing:  &testFormBilling{Account: "123", Bank: "CITY"},
+		}
+		require.NoError(t, EncodeForm(f, &want, EncodeFormOptions{Anchor: "B2"}))
+
+		got, err := f.File.GetRows(DefaultSheetName)
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			nil,
+			{"", "Customer", "ACME"},
+			{"", "Date", "03-01-24"},
+			{"", "Amount", "10.5"},
+			{"", "Paid", "TRUE"},
+			{"", "Note"},
+			nil,
+			{"", "Billing"},
+			{"", "Account", "123"},
+			{"", "Bank", "CITY"},
+		}, got)
+
+		var res testForm
+		require.NoError(t, DecodeForm(&Read{File: f.File}, &res, DecodeFormOptions{Anchor: "B2"}))
+		assert.Equal(t, want, res)
+	})
+
+	t.Run("decode skips cell fields", func(t *testing.T) {
+		t.Parallel()
+
+		type v struct {
+			Name  string            `excel:"name"`
+			Link  string            `excel:"name,hyperlink"`
+			Row   int               `excel:",row"`
+			Cells map[string]string `excel:",cells"`
+			Calc  string            `excel:"calc,calc"`
+		}
+
+		r := newTestRead(t, [][]any{{"name", "ACME"}, {"calc", 5}})
+		require.NoError(t, r.SetCellFormula(DefaultSheetName, "B2", "1+1")) // cached value is 5, calculated is 2
+
+		var got v
+		require.NoError(t, DecodeForm(r, &got, DecodeFormOptions{}))
+		assert.Equal(t, v{Name: "ACME", Calc: "5"}, got)
+	})
+
+	t.Run("decode", func(t *testing.T) {
+		t.Parallel()
+
+		r := newTestRead(t, [][]any{
+			{"Invoice"},
+			{"customer", " ACME "},
+			{"amount", 7},
+			{},
+			{"billing"},
+			{"account", "9"},
+			{"bank", "city"},
+			{},
+			{"paid", "true"},
+		})
+
+		var got testForm
+		require.NoError(t, DecodeForm(r, &got, DecodeFormOptions{Anchor: "A2", TitleConv: func(title string) string {
+			return strings.ToUpper(title[:1]) + title[1:]
+		}}))
+		assert.Equal(t, testForm{
+			Customer: "ACME",
+			Amount:   7,
+			Paid:     true,
+			Billing:  &testFormBilling{Account: "9", Bank: "CITY"},
+		}, got)
+	})
+}
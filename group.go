@@ -0,0 +1,243 @@
+package excelstruct
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// groupTitle returns the title of the field in the n-th group, the number replaces {n} of the pattern.
+// e.g. pattern "Item{n} " and field "Qty" give "Item2 Qty" for the second group.
+func groupTitle(pattern string, n int, name string) string {
+	return strings.ReplaceAll(pattern, groupNumber, strconv.Itoa(n)) + name
+}
+
+// groupStruct returns the struct type of the slice elements.
+func groupStruct(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Slice {
+		return nil, false
+	}
+
+	elem := t.Elem()
+	if elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	return elem, elem.Kind() == reflect.Struct && elem != timeType
+}
+
+// groupFields returns the value fields of the group struct.
+func groupFields(t reflect.Type, tag string) []field {
+	var ff []field
+	for _, f := range cachedTypeFields(t, typeOpts{structTag: tag}).list {
+		if f.isValue() {
+			ff = append(ff, f)
+		}
+	}
+	return ff
+}
+
+// group decodes the repeated column groups into the slice of structs, the empty trailing groups are dropped.
+func (d *decodeState) group(f *field, data []string, v reflect.Value, unmarshalError *UnmarshalError) {
+	st, ok := groupStruct(v.Type())
+	if !ok {
+		unmarshalError.saveError(&UnmarshalTypeError{
+			Type:  v.Type(),
+			Field: f.name,
+			Err:   fmt.Errorf("group requires slice of structs"),
+		})
+		return
+	}
+
+	fields := groupFields(st, d.opts.tag)
+	items := reflect.MakeSlice(v.Type(), 0, 0)
+	last := 0 // number of groups up to the last non-empty one
+	for n := 1; ; n++ {
+		item := reflect.New(st)
+		found, empty := false, true
+		for i := range fields {
+			sf := &fields[i]
+			name := groupTitle(f.group, n, sf.name)
+			col, ok := d.sheet.title.columnIndex(name)
+			if !ok {
+				continue
+			}
+			found = true
+
+			formula := sf.formula
+			if formula == "" {
+				formula = d.opts.formula
+			}
+
+			d.field = name
+			value, err := d.columnValues(col, data, formula)
+			if err != nil {
+				unmarshalError.saveError(err)
+				continue
+			}
+
+			if len(value) == 0 {
+				continue
+			}
+			empty = false
+
+			if len(sf.conv) > 0 {
+				if err := d.convert(sf, value); err != nil {
+					unmarshalError.saveError(err)
+					continue
+				}
+			}
+
+			subv, err := fieldByIndex(item.Elem(), sf.index)
+			if err != nil {
+				unmarshalError.saveError(err)
+				continue
+			}
+
+			d.col = col
+			if err := d.value(value, subv); err != nil {
+				unmarshalError.saveError(err)
+			}
+		}
+
+		if !found {
+			break
+		}
+
+		if v.Type().Elem().Kind() != reflect.Pointer {
+			item = item.Elem()
+		}
+		items = reflect.Append(items, item)
+		if !empty {
+			last = n
+		}
+	}
+
+	if last == 0 {
+		v.SetZero()
+		return
+	}
+	v.Set(items.Slice(0, last))
+}
+
+// group writes the slice of structs into the repeated column groups, the columns of new groups are inserted.
+func (e *encodeState) group(f *field, v reflect.Value, opts encOpts) {
+	st, ok := groupStruct(v.Type())
+	if !ok {
+		e.error(fmt.Errorf("excelstruct: field %q group requires slice of structs", f.name))
+	}
+
+	if e.orient != OrientationRow {
+		e.error(fmt.Errorf("excelstruct: field %q group requires row orientation", f.name))
+	}
+
+	fields := groupFields(st, e.typeOpts.structTag)
+	names := make([]string, 0, len(fields))
+	for _, sf := range fields {
+		names = append(names, sf.name)
+	}
+
+	for n := 1; n <= v.Len(); n++ {
+		// the columns of the nil item are written empty to keep the next groups in order
+		if err := e.title.growGroup(f.group, names, n); err != nil {
+			e.error(fmt.Errorf("excelstruct: field %q group %d: %w", f.name, n, err))
+		}
+
+		item := v.Index(n - 1)
+		if item.Kind() == reflect.Pointer {
+			if item.IsNil() {
+				continue
+			}
+			item = item.Elem()
+		}
+
+	FieldLoop:
+		for i := range fields {
+			sf := &fields[i]
+
+			// find the nested struct field by following sf.index.
+			fv := item
+			for _, i := range sf.index {
+				if fv.Kind() == reflect.Pointer {
+					if fv.IsNil() {
+						continue FieldLoop
+					}
+					fv = fv.Elem()
+				}
+				fv = fv.Field(i)
+			}
+
+			if sf.omitEmpty && isEmptyValue(fv) {
+				continue
+			}
+
+			if !e.setField(groupTitle(f.group, n, sf.name)) {
+				continue
+			}
+
+			fopts := opts
+			fopts.conv = sf.conv
+			sf.encoder(e, fv, fopts)
+		}
+	}
+}
+
+// growGroup inserts the columns of the n-th group after the previous group if the group has no titles.
+func (t *title) growGroup(pattern string, names []string, n int) error {
+	endCol, pos := 0, -1
+	for _, name := range names {
+		if _, ok := t.idx[groupTitle(pattern, n, name)]; ok {
+			return nil
+		}
+
+		if idx, ok := t.idx[groupTitle(pattern, n-1, name)]; ok {
+			col := t.name[idx].Column
+			endCol = max(endCol, col[len(col)-1])
+			pos = max(pos, idx)
+		}
+	}
+
+	// the previous group is not written
+	if pos == -1 {
+		return nil
+	}
+
+	if err := t.insertCols(endCol+1, len(names)); err != nil {
+		return fmt.Errorf("insert column: %w", err)
+	}
+
+	for i := range t.name {
+		for j, c := range t.name[i].Column {
+			if c > endCol {
+				t.name[i].Column[j] += len(names)
+			}
+		}
+	}
+
+	added := make([]titleName, 0, len(names))
+	for i, name := range names {
+		added = append(added, titleName{
+			Name:    groupTitle(pattern, n, name),
+			Column:  []int{endCol + 1 + i},
+			Width:   map[int]float64{},
+			RowData: map[int]int{0: t.config.rowIndex}, // pointer to the header
+		})
+
+		if nf, ok := t.numFmt[groupTitle(pattern, n-1, name)]; ok {
+			t.numFmt[groupTitle(pattern, n, name)] = nf
+		}
+	}
+
+	t.name = slices.Insert(t.name, pos+1, added...)
+	for i, v := range t.name {
+		t.idx[v.Name] = i
+	}
+
+	for _, v := range added {
+		if err := t.writeTitle(v.Name); err != nil {
+			return fmt.Errorf("title %q write: %w", v.Name, err)
+		}
+	}
+	return nil
+}
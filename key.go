@@ -0,0 +1,119 @@
+package excelstruct
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// keySeparator joins the values of the composite key.
+const keySeparator = "\x1f"
+
+// A DuplicateKeyError describes a row whose key is equal to the key of a previous row.
+type DuplicateKeyError struct {
+	Key        []string // values of the key fields
+	Sheet      string
+	Row        int
+	FirstSheet string
+	FirstRow   int
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("excelstruct: duplicate key %q in sheet %q row %d, first in sheet %q row %d",
+		strings.Join(e.Key, ", "), e.Sheet, e.Row, e.FirstSheet, e.FirstRow)
+}
+
+// keyFields returns the fields with the tag option "key" in order of the struct.
+func keyFields(t reflect.Type, tag string) []field {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var ff []field
+	for _, f := range cachedTypeFields(t, typeOpts{structTag: tag}).list {
+		if f.key {
+			ff = append(ff, f)
+		}
+	}
+	return ff
+}
+
+// keyValues returns the values of the key fields, the nil pointer is the empty value.
+func keyValues(v reflect.Value, fields []field) []string {
+	values := make([]string, 0, len(fields))
+	for _, f := range fields {
+		fv := fieldValue(v, f.index)
+		if !fv.IsValid() {
+			values = append(values, "")
+			continue
+		}
+
+		if fv.Kind() == reflect.Pointer {
+			fv = fv.Elem()
+		}
+		values = append(values, fmt.Sprint(fv.Interface()))
+	}
+	return values
+}
+
+// keyPosition is the position of the row with the key.
+type keyPosition struct {
+	sheet string
+	row   int
+}
+
+// keySet tracks the keys of the decoded rows.
+type keySet struct {
+	fields []field
+	seen   map[string]keyPosition
+}
+
+// newKeySet returns the set of keys, nil if the struct has no key fields.
+func newKeySet(t reflect.Type, tag string) *keySet {
+	fields := keyFields(t, tag)
+	if len(fields) == 0 {
+		return nil
+	}
+	return &keySet{fields: fields, seen: make(map[string]keyPosition)}
+}
+
+// empty reports whether the cells of the key fields are empty, the row with the empty key is not tracked.
+func (s *keySet) empty(column []string, t *title) bool {
+	for _, f := range s.fields {
+		col, _ := t.columnIndex(f.name)
+		for _, c := range col {
+			if c <= len(column) && !isEmptyString(strings.TrimSpace(column[c-1])) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// add adds the key of the value and returns the error if the key was added by another row.
+func (s *keySet) add(sheet string, row int, v reflect.Value) error {
+	values := keyValues(v, s.fields)
+	key := strings.Join(values, keySeparator)
+
+	first, ok := s.seen[key]
+	if !ok {
+		s.seen[key] = keyPosition{sheet: sheet, row: row}
+		return nil
+	}
+
+	// the row is read again after the seek
+	if first.sheet == sheet && first.row == row {
+		return nil
+	}
+
+	return &UnmarshalError{
+		Row: row,
+		Err: []error{&DuplicateKeyError{
+			Key:        values,
+			Sheet:      sheet,
+			Row:        row,
+			FirstSheet: first.sheet,
+			FirstRow:   first.row,
+		}},
+	}
+}
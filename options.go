@@ -9,6 +9,8 @@ import (
 
 const DefaultSheetName = "Sheet1"
 
+const defaultFormAnchor = "A1"
+
 type Orientation string
 
 const (
@@ -16,6 +18,24 @@ const (
 	OrientationColumn Orientation = "col"
 )
 
+// BlankRow is the policy of the decoder for fully empty rows.
+type BlankRow string
+
+const (
+	BlankRowKeep BlankRow = "keep" // decode as a zero value
+	BlankRowSkip BlankRow = "skip" // skip the row
+	BlankRowStop BlankRow = "stop" // stop at the first empty row
+)
+
+// Formula is the value of formula cells which the decoder reads.
+type Formula string
+
+const (
+	FormulaCached Formula = "cached" // value saved in the file
+	FormulaText   Formula = "text"   // text of the formula
+	FormulaCalc   Formula = "calc"   // value recalculated by excelize
+)
+
 const (
 	defaultTimeFormat = "01-02-06"
 )
@@ -24,6 +44,12 @@ var defaultCellType = map[excelize.CellType]int{
 	excelize.CellTypeDate: 14, // "dd.mm.yyyy"
 }
 
+var defaultConv = NameConv{
+	"trim":  {Read: trimConv, Write: trimConv},
+	"upper": {Read: upperConv, Write: upperConv},
+	"lower": {Read: lowerConv, Write: lowerConv},
+}
+
 // TitleConv is the function to convert title name.
 type TitleConv func(title string) string
 
@@ -54,6 +80,22 @@ type ReadBoolConv func(title string, v string) (bool, error)
 // ReadTimeConv is the function to convert value to time.Time.
 type ReadTimeConv func(v string) (time.Time, error)
 
+// EndOfData is the function to detect the row after the last row of data, such as a footer "Total".
+type EndOfData func(column []string) bool
+
+// ChildRow is the function to detect the child row of the previous parent row.
+type ChildRow func(column []string) bool
+
+// ValueConv is the pair of functions to convert a cell value of the field selected by the tag option "conv".
+// Read is applied on decode, Write is applied on encode. A nil function keeps the value as is.
+type ValueConv struct {
+	Read  func(v string) (string, error)
+	Write func(v string) (string, error)
+}
+
+// NameConv is the naming converters to select by the tag option "conv".
+type NameConv map[string]ValueConv
+
 // DefaultScaleAutoWidth is the scale function for default font size.
 //
 // PR: https://github.com/qax-os/excelize/pull/1386
@@ -68,6 +110,18 @@ func defaultTitleConv(title string) string {
 	return title
 }
 
+func trimConv(v string) (string, error) {
+	return strings.TrimSpace(v), nil
+}
+
+func upperConv(v string) (string, error) {
+	return strings.ToUpper(v), nil
+}
+
+func lowerConv(v string) (string, error) {
+	return strings.ToLower(v), nil
+}
+
 func defaultTimeConv(v string) (time.Time, error) {
 	et, err := time.Parse(defaultTimeFormat, v)
 	if err != nil {
@@ -83,6 +137,14 @@ type OpenFileOptions struct {
 	Excel    *excelize.Options
 }
 
+// excelOptions returns the options of excelize.
+func (o OpenFileOptions) excelOptions() []excelize.Options {
+	if o.Excel == nil {
+		return nil
+	}
+	return []excelize.Options{*o.Excel}
+}
+
 // WriteFileOptions is the options for write file.
 type WriteFileOptions struct {
 	FilePath  string
@@ -96,6 +158,14 @@ func (o *WriteFileOptions) initDefault() {
 	}
 }
 
+// excelOptions returns the options of excelize.
+func (o WriteFileOptions) excelOptions() []excelize.Options {
+	if o.Excel == nil {
+		return nil
+	}
+	return []excelize.Options{*o.Excel}
+}
+
 // EncoderOptions is the options for write workspace.
 type EncoderOptions struct {
 	SheetName             string
@@ -109,8 +179,18 @@ type EncoderOptions struct {
 	ValidationOverRow     int
 	StringConv            WriteStringConv
 	BoolConv              WriteBoolConv
+	Conv                  NameConv
 	Orientation           Orientation
 
+	// Append continues the existing sheet: the title is read at TitleRowIndex,
+	// the rows are written after the last data row with its styles.
+	Append bool
+
+	// Upsert updates the row of the existing sheet whose key fields are equal and appends the other values, it implies Append.
+	// The key fields are set by the tag option "key", the columns which are not in the struct are not changed,
+	// the values with the empty key are always appended.
+	Upsert bool
+
 	CellNumFmt  map[excelize.CellType]int
 	TitleNumFmt map[string]int
 
@@ -136,6 +216,10 @@ func (o *EncoderOptions) initDefault() {
 		o.Orientation = OrientationRow
 	}
 
+	if o.Upsert {
+		o.Append = true
+	}
+
 	// merge with a default cell type
 	if len(o.CellNumFmt) == 0 {
 		o.CellNumFmt = make(map[excelize.CellType]int, len(defaultCellType))
@@ -151,6 +235,8 @@ func (o *EncoderOptions) initDefault() {
 		o.TitleNumFmt = make(map[string]int)
 	}
 
+	o.Conv = mergeConv(o.Conv)
+
 	if o.TitleScaleAutoWidth != nil {
 		o.TitleMaxWidth = infinityTitleMaxWith
 	}
@@ -188,7 +274,48 @@ type DecoderOptions struct {
 	StringConv    ReadStringConv
 	BoolConv      ReadBoolConv
 	TimeConv      ReadTimeConv
+	Conv          NameConv
 	StructTag     string
+
+	// FillMergedCells fills every cell covered by a merged cell with the merged value.
+	FillMergedCells bool
+	// SkipHiddenRows skips the hidden rows including rows hidden by a filter.
+	SkipHiddenRows bool
+	// SkipHiddenColumns excludes the hidden columns from the title matching.
+	SkipHiddenColumns bool
+	// BlankRow is the policy for fully empty rows, by default BlankRowKeep.
+	BlankRow BlankRow
+	// EndOfData stops the decoder at the row which matches.
+	EndOfData EndOfData
+	// DataStartRow is the first row of data, by default the row after the title.
+	DataStartRow int
+	// DataEndRow is the last row of data (0 - no limit).
+	DataEndRow int
+	// Offset is the number of data rows to skip.
+	Offset int
+	// Limit is the maximum number of data rows (0 - no limit).
+	Limit int
+	// FirstColumn and LastColumn bound the columns of the title and data (0 - no limit).
+	FirstColumn int
+	LastColumn  int
+	// TableName reads the table (ListObject), the sheet, the title row and the data range are taken from the table.
+	TableName string
+	// DefinedName reads the range of the defined name, the first row of the range is the title.
+	DefinedName string
+	// SheetNames and SheetPattern read the sheets one by one, the title is read on each sheet.
+	// The pattern has the syntax of path.Match. The options of rows are applied to each sheet.
+	SheetNames   []string
+	SheetPattern string
+	// Formula is the value of formula cells, by default FormulaCached.
+	// The tag options "cached", "calc" and "formula" override it for the field.
+	Formula Formula
+	// ChildOutline, ChildMarker and ChildRow recognise the child rows following a parent row,
+	// the child rows are decoded into the field with the tag option "children".
+	// A row is the child if the outline level is greater than 0, the value of the marker title is empty
+	// or the function matches.
+	ChildOutline bool
+	ChildMarker  string
+	ChildRow     ChildRow
 }
 
 func (o *DecoderOptions) initDefault() {
@@ -200,6 +327,10 @@ func (o *DecoderOptions) initDefault() {
 		o.TitleRowIndex = 1
 	}
 
+	if o.DataStartRow <= o.TitleRowIndex {
+		o.DataStartRow = o.TitleRowIndex + 1
+	}
+
 	if o.TitleConv == nil {
 		o.TitleConv = defaultTitleConv
 	}
@@ -211,4 +342,105 @@ func (o *DecoderOptions) initDefault() {
 	if o.TimeConv == nil {
 		o.TimeConv = defaultTimeConv
 	}
+
+	if o.BlankRow == "" {
+		o.BlankRow = BlankRowKeep
+	}
+
+	if o.Formula == "" {
+		o.Formula = FormulaCached
+	}
+
+	o.Conv = mergeConv(o.Conv)
+}
+
+// hasChildren reports whether the child rows are recognised.
+func (o *DecoderOptions) hasChildren() bool {
+	return o.ChildOutline || o.ChildMarker != "" || o.ChildRow != nil
+}
+
+// DecodeFormOptions is the options for reading the form sheet.
+type DecodeFormOptions struct {
+	SheetName  string
+	Anchor     string // cell of the first label, by default A1
+	TitleConv  TitleConv
+	StringConv ReadStringConv
+	BoolConv   ReadBoolConv
+	TimeConv   ReadTimeConv
+	Conv       NameConv
+	StructTag  string
+}
+
+func (o *DecodeFormOptions) initDefault() {
+	if o.SheetName == "" {
+		o.SheetName = DefaultSheetName
+	}
+
+	if o.Anchor == "" {
+		o.Anchor = defaultFormAnchor
+	}
+
+	if o.TitleConv == nil {
+		o.TitleConv = defaultTitleConv
+	}
+
+	if o.TimeConv == nil {
+		o.TimeConv = defaultTimeConv
+	}
+
+	if o.StructTag == "" {
+		o.StructTag = defaultTag
+	}
+
+	o.Conv = mergeConv(o.Conv)
+}
+
+// EncodeFormOptions is the options for writing the form sheet.
+type EncodeFormOptions struct {
+	SheetName  string
+	Anchor     string // cell of the first label, by default A1
+	TitleConv  TitleConv
+	StringConv WriteStringConv
+	BoolConv   WriteBoolConv
+	Conv       NameConv
+	CellNumFmt map[excelize.CellType]int
+}
+
+func (o *EncodeFormOptions) initDefault() {
+	if o.SheetName == "" {
+		o.SheetName = DefaultSheetName
+	}
+
+	if o.Anchor == "" {
+		o.Anchor = defaultFormAnchor
+	}
+
+	if o.TitleConv == nil {
+		o.TitleConv = defaultTitleConv
+	}
+
+	cellNumFmt := make(map[excelize.CellType]int, len(defaultCellType)+len(o.CellNumFmt))
+	for k, v := range defaultCellType {
+		cellNumFmt[k] = v
+	}
+
+	for k, v := range o.CellNumFmt {
+		cellNumFmt[k] = v
+	}
+	o.CellNumFmt = cellNumFmt
+
+	o.Conv = mergeConv(o.Conv)
+}
+
+// mergeConv merges the converters with default converters, the user converter has a priority.
+func mergeConv(conv NameConv) NameConv {
+	res := make(NameConv, len(defaultConv)+len(conv))
+	for k, v := range defaultConv {
+		res[k] = v
+	}
+
+	for k, v := range conv {
+		res[k] = v
+	}
+	return res
 }
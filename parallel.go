@@ -0,0 +1,161 @@
+package excelstruct
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+// ParallelOptions is the options for parallel decoding.
+type ParallelOptions struct {
+	Workers   int  // number of goroutines decoding rows, by default GOMAXPROCS
+	Unordered bool // rows are returned as soon as they are decoded
+}
+
+func (o *ParallelOptions) initDefault() {
+	if o.Workers < 1 {
+		o.Workers = runtime.GOMAXPROCS(0)
+	}
+}
+
+// rawRow is the row read by the cursor.
+type rawRow struct {
+	seq    int
+	row    int
+	sheet  *sheetState
+	column []string
+	err    error
+}
+
+// decodedRow is the row decoded by a worker.
+type decodedRow[T any] struct {
+	seq      int
+	sheet    string
+	row      Row[T]
+	emptyKey bool // the cells of the key fields are empty
+	err      error
+}
+
+// ParallelRows returns the iterator over the rows, one goroutine reads the rows and the pool of workers decodes them.
+// By default, the rows are returned in the original order. The cursor is closed when the iteration ends.
+func (c *Decoder[T]) ParallelRows(ctx context.Context, opts ParallelOptions) iter.Seq2[Row[T], error] {
+	opts.initDefault()
+
+	return func(yield func(Row[T], error) bool) {
+		if c.children != nil {
+			yield(Row[T]{}, fmt.Errorf("excelstruct: parallel rows do not support children"))
+			return
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		jobs := make(chan rawRow, opts.Workers)
+		results := make(chan decodedRow[T], opts.Workers)
+		readDone := make(chan struct{})
+
+		// copy before the reader changes the state of the next sheet
+		base := *c.dec
+
+		var readErr error
+		go func() {
+			defer close(readDone)
+			defer close(jobs)
+
+			for seq := 0; c.Next(); seq++ {
+				column, err := c.cursor.Columns()
+				select {
+				case jobs <- rawRow{seq: seq, row: c.cursor.row, sheet: c.dec.sheet, column: column, err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+			readErr = c.Err()
+		}()
+
+		var wg sync.WaitGroup
+		for range opts.Workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				dec := base
+				for job := range jobs {
+					res := decodedRow[T]{seq: job.seq, sheet: job.sheet.name, row: Row[T]{Index: job.row}}
+					if job.err != nil {
+						res.err = fmt.Errorf("excelstruct: get columns: %w", job.err)
+					} else {
+						dec.row = job.row
+						dec.sheet = job.sheet
+						res.err = dec.unmarshal(job.column, &res.row.Value)
+						res.emptyKey = c.keys != nil && c.keys.empty(job.column, job.sheet.title)
+					}
+
+					select {
+					case results <- res:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+		}
+
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		defer func() {
+			cancel()
+			for range results {
+			}
+			<-readDone
+			c.Close()
+		}()
+
+		// the keys are checked in order of yield
+		checkKey := func(res *decodedRow[T]) {
+			if res.err == nil && c.keys != nil && !res.emptyKey {
+				res.err = c.keys.add(res.sheet, res.row.Index, reflect.ValueOf(&res.row.Value).Elem())
+			}
+		}
+
+		pending := make(map[int]decodedRow[T])
+		next := 0
+		for res := range results {
+			if opts.Unordered {
+				checkKey(&res)
+				if !yield(res.row, res.err) {
+					return
+				}
+				continue
+			}
+
+			pending[res.seq] = res
+			for {
+				res, ok := pending[next]
+				if !ok {
+					break
+				}
+
+				delete(pending, next)
+				next++
+				checkKey(&res)
+				if !yield(res.row, res.err) {
+					return
+				}
+			}
+		}
+
+		<-readDone
+		if err := ctx.Err(); err != nil {
+			yield(Row[T]{}, fmt.Errorf("excelstruct: %w", err))
+			return
+		}
+
+		if readErr != nil {
+			yield(Row[T]{}, readErr)
+		}
+	}
+}
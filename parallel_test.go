@@ -0,0 +1,97 @@
+package excelstruct
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder_ParallelRows(t *testing.T) {
+	t.Parallel()
+
+	type v struct {
+		N int `excel:"n"`
+	}
+
+	rows := [][]any{{"n"}}
+	var want []Row[v]
+	for i := 1; i <= 100; i++ {
+		rows = append(rows, []any{i})
+		want = append(want, Row[v]{Index: i + 1, Value: v{i}})
+	}
+	rows = append(rows, []any{"x"})
+	f := newTestRead(t, rows)
+
+	t.Run("ordered", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{})
+		require.NoError(t, err)
+
+		var (
+			got  []Row[v]
+			errs int
+		)
+		for row, err := range sheet.ParallelRows(context.Background(), ParallelOptions{Workers: 4}) {
+			if err != nil {
+				errs++
+				continue
+			}
+			got = append(got, row)
+		}
+		assert.Equal(t, want, got)
+		assert.Equal(t, 1, errs)
+	})
+
+	t.Run("unordered", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{})
+		require.NoError(t, err)
+
+		var got []Row[v]
+		for row, err := range sheet.ParallelRows(context.Background(), ParallelOptions{Workers: 4, Unordered: true}) {
+			if err == nil {
+				got = append(got, row)
+			}
+		}
+		sort.Slice(got, func(i, j int) bool { return got[i].Index < got[j].Index })
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("break", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{})
+		require.NoError(t, err)
+
+		var got []Row[v]
+		for row, err := range sheet.ParallelRows(context.Background(), ParallelOptions{Workers: 2}) {
+			require.NoError(t, err)
+			got = append(got, row)
+			if len(got) == 10 {
+				break
+			}
+		}
+		assert.Equal(t, want[:10], got)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		t.Parallel()
+
+		sheet, err := NewDecoder[v](f, DecoderOptions{})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var last error
+		for _, err := range sheet.ParallelRows(ctx, ParallelOptions{}) {
+			last = err
+		}
+		assert.ErrorIs(t, last, context.Canceled)
+	})
+}
@@ -0,0 +1,175 @@
+package excelstruct
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// refKeys is the keys of the referenced columns by the reference "Sheet.title".
+type refKeys map[string]map[string]struct{}
+
+// check returns the errors of the values which are not found in the referenced column.
+// The values are not checked if the keys of the reference are not read.
+func (r refKeys) check(f *field, values []string) []error {
+	keys, ok := r[f.ref]
+	if !ok {
+		return nil
+	}
+
+	var errs []error
+	for _, v := range values {
+		if _, ok := keys[v]; !ok {
+			errs = append(errs, &ReferenceError{Value: v, Field: f.name, Ref: f.ref})
+		}
+	}
+	return errs
+}
+
+// refColumn is the key column of the referenced sheet.
+type refColumn struct {
+	sheetName string
+	col       int
+	titleRow  int
+	lastRow   int // last row with a value, the title row if there is no value
+	keys      map[string]struct{}
+}
+
+// sqref returns the absolute range of the values, e.g. 'Customers'!$A$2:$A$10.
+func (c refColumn) sqref() string {
+	start, _ := excelize.CoordinatesToCellName(c.col, c.titleRow+1, true)
+	end, _ := excelize.CoordinatesToCellName(c.col, max(c.lastRow, c.titleRow+1), true)
+	return fmt.Sprintf("'%s'!%s:%s", strings.ReplaceAll(c.sheetName, "'", "''"), start, end)
+}
+
+// splitRef splits the reference "Sheet.title" by the last dot.
+func splitRef(ref string) (string, string, error) {
+	i := strings.LastIndex(ref, ".")
+	if i <= 0 || i == len(ref)-1 {
+		return "", "", fmt.Errorf("ref %q must be \"Sheet.title\"", ref)
+	}
+	return ref[:i], ref[i+1:], nil
+}
+
+// refFields returns the value fields with the tag option "ref".
+func refFields(t reflect.Type, tag string) []field {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var ff []field
+	for _, f := range cachedTypeFields(t, typeOpts{structTag: tag}).list {
+		if f.isValue() && f.ref != "" {
+			ff = append(ff, f)
+		}
+	}
+	return ff
+}
+
+// readRefKeys reads the keys of the columns referenced by the struct fields.
+func readRefKeys(file *excelize.File, t reflect.Type, tag string, conv TitleConv) (refKeys, error) {
+	refs := make(refKeys)
+	for _, f := range refFields(t, tag) {
+		if _, ok := refs[f.ref]; ok {
+			continue
+		}
+
+		c, err := findRefColumn(file, f.ref, func(cell, title string) bool { return conv(cell) == title })
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", f.name, err)
+		}
+		refs[f.ref] = c.keys
+	}
+	return refs, nil
+}
+
+// findRefColumn finds the first row of the sheet with the title and reads the values below it,
+// the cell of the title is matched by the function.
+func findRefColumn(file *excelize.File, ref string, match func(cell, title string) bool) (refColumn, error) {
+	sheetName, title, err := splitRef(ref)
+	if err != nil {
+		return refColumn{}, err
+	}
+
+	rows, err := file.Rows(sheetName)
+	if err != nil {
+		return refColumn{}, fmt.Errorf("ref %q rows: %w", ref, err)
+	}
+	defer rows.Close()
+
+	c := refColumn{sheetName: sheetName, keys: make(map[string]struct{})}
+	for row := 1; rows.Next(); row++ {
+		column, err := rows.Columns()
+		if err != nil {
+			return refColumn{}, fmt.Errorf("ref %q row %d columns: %w", ref, row, err)
+		}
+
+		if c.col == 0 {
+			for i, v := range column {
+				if match(strings.TrimSpace(v), title) {
+					c.col, c.titleRow, c.lastRow = i+1, row, row
+					break
+				}
+			}
+			continue
+		}
+
+		if c.col > len(column) {
+			continue
+		}
+
+		if v := strings.TrimSpace(column[c.col-1]); !isEmptyString(v) {
+			c.keys[v] = struct{}{}
+			c.lastRow = row
+		}
+	}
+
+	if err := rows.Error(); err != nil {
+		return refColumn{}, fmt.Errorf("ref %q rows: %w", ref, err)
+	}
+
+	if c.col == 0 {
+		return refColumn{}, fmt.Errorf("ref %q title not found", ref)
+	}
+	return c, nil
+}
+
+// writeRefValidation adds the list data validation of the referenced keys to the columns of the fields with the tag option "ref".
+// The referenced sheet must be written before.
+func (t *title) writeRefValidation(fields []field) error {
+	maxRow := t.maxRowData()
+	if t.config.validationOverRow > 0 {
+		maxRow += t.config.validationOverRow
+	}
+
+	// no needs a data validation because there is no written data
+	if maxRow < t.firstDataRow() {
+		return nil
+	}
+
+	for _, f := range fields {
+		col, ok := t.columnIndex(f.name)
+		if !ok {
+			continue
+		}
+
+		// the referenced title is written by the same conversion of the encoder
+		c, err := findRefColumn(t.file, f.ref, func(cell, title string) bool { return cell == t.config.conv(title) })
+		if err != nil {
+			return fmt.Errorf("title %q: %w", f.name, err)
+		}
+
+		from, _ := excelize.CoordinatesToCellName(col[0], t.firstDataRow())
+		to, _ := excelize.CoordinatesToCellName(col[len(col)-1], maxRow)
+
+		dv := excelize.NewDataValidation(true)
+		dv.Sqref = from + ":" + to
+		dv.SetSqrefDropList(c.sqref())
+		if err := t.file.AddDataValidation(t.config.sheetName, dv); err != nil {
+			return fmt.Errorf("title %q %q data validation: %w", f.name, dv.Sqref, err)
+		}
+	}
+	return nil
+}
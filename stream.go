@@ -0,0 +1,295 @@
+package excelstruct
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrStreamUnsupported is returned by the stream encoder for the features which need random access to cells,
+// such as inserting columns of slices and groups, the column orientation and the outline levels of children.
+var ErrStreamUnsupported = errors.New("not supported by the stream encoder")
+
+// streamWidthRows is the number of the first rows which are buffered to measure the width of columns,
+// the width of columns can not be changed after the first row is written by the stream writer.
+const streamWidthRows = 100
+
+// streamRow is the values of the row written by the stream writer.
+type streamRow struct {
+	row    int
+	values []any
+}
+
+// set sets the value of the column.
+func (r *streamRow) set(col int, value any) {
+	for len(r.values) < col {
+		r.values = append(r.values, nil)
+	}
+	r.values[col-1] = value
+}
+
+// StreamEncoder is a writing data to a sheet by the stream writer, the memory does not grow with the number of rows.
+// The rows are written in order, the features which need random access to cells return ErrStreamUnsupported.
+// The sheet must not be changed by other functions until the encoder is closed.
+type StreamEncoder[T any] struct {
+	*excelize.File
+	sw       *excelize.StreamWriter
+	enc      *encodeState
+	styles   []int       // style of columns by index
+	buffered []streamRow // first rows to measure the width of columns
+	widthSet bool
+	close    bool
+	err      error
+}
+
+// NewStreamEncoder creates the stream encoder of the new sheet with the specified titles and struct.
+func NewStreamEncoder[T any](w *Write, opts EncoderOptions) (*StreamEncoder[T], error) {
+	opts.initDefault()
+
+	if err := opts.Conv.check(reflect.TypeFor[T](), w.config.structTag); err != nil {
+		return nil, fmt.Errorf("excelstruct: %w", err)
+	}
+
+	if opts.Orientation != OrientationRow {
+		return nil, fmt.Errorf("excelstruct: orientation %q: %w", opts.Orientation, ErrStreamUnsupported)
+	}
+
+	if opts.Append {
+		return nil, fmt.Errorf("excelstruct: append: %w", ErrStreamUnsupported)
+	}
+
+	children, err := childrenField(reflect.TypeFor[T](), w.config.structTag)
+	if err != nil {
+		return nil, fmt.Errorf("excelstruct: %w", err)
+	}
+
+	if children != nil {
+		return nil, fmt.Errorf("excelstruct: field %q children: %w", children.name, ErrStreamUnsupported)
+	}
+
+	if _, err := w.File.NewSheet(opts.SheetName); err != nil {
+		return nil, fmt.Errorf("excelstruct: new sheet %q: %w", opts.SheetName, err)
+	}
+
+	config := encoderTitleConfig(w, opts)
+	config.stream = true
+	title, err := newTitleFromStruct[T](config, w.File)
+	if err != nil {
+		return nil, fmt.Errorf("excelstruct: init title: %w", err)
+	}
+
+	sw, err := w.File.NewStreamWriter(opts.SheetName)
+	if err != nil {
+		return nil, fmt.Errorf("excelstruct: stream writer %q: %w", opts.SheetName, err)
+	}
+
+	var styles []int
+	header := streamRow{row: opts.TitleRowIndex}
+	for _, n := range title.name {
+		styleID, err := newTitleStyle(title, n.Name, opts.CellStyle, opts.Style)
+		if err != nil {
+			return nil, fmt.Errorf("excelstruct: title %q: %w", n.Name, err)
+		}
+
+		for _, c := range n.Column {
+			for len(styles) < c {
+				styles = append(styles, 0)
+			}
+			styles[c-1] = styleID
+			header.set(c, title.config.conv(n.Name))
+		}
+	}
+
+	e := &StreamEncoder[T]{
+		File: w.File,
+		sw:   sw,
+		enc: &encodeState{
+			encOpts: encOpts{
+				stringConv: opts.StringConv,
+				boolConv:   opts.BoolConv,
+				nameConv:   opts.Conv,
+			},
+			typeOpts: typeOpts{
+				structTag: w.config.structTag,
+			},
+			orient:                opts.Orientation,
+			disallowUnknownFields: opts.DisallowUnknownFields,
+			title:                 title,
+			file:                  w.File,
+			row:                   opts.TitleRowIndex + 1, // position the first row of data
+		},
+		styles: styles,
+	}
+
+	if err := e.writeRow(header); err != nil {
+		return nil, err
+	}
+	w.track(opts.SheetName, e)
+	return e, nil
+}
+
+// Encode writes v to the next row.
+func (e *StreamEncoder[T]) Encode(v *T) error {
+	if e.close {
+		return fmt.Errorf("excelstruct: encoder is closed")
+	}
+
+	row := &streamRow{row: e.enc.row}
+	e.enc.stream = row
+	if err := e.enc.marshal(v); err != nil {
+		return err
+	}
+	return e.writeRow(*row)
+}
+
+// All writes all values to the sheet.
+func (e *StreamEncoder[T]) All(v []T) error {
+	for i := range v {
+		if err := e.Encode(&v[i]); err != nil {
+			return fmt.Errorf("excelstruct: marshal: %w", err)
+		}
+	}
+	return nil
+}
+
+// SqrefRow returns the range of the row by title.
+func (e *StreamEncoder[T]) SqrefRow(title string) (string, error) {
+	return e.enc.title.sqrefByRow(title)
+}
+
+// Close writes the buffered rows, the data validation and flushes the stream writer.
+func (e *StreamEncoder[T]) Close() (err error) {
+	if e.close {
+		return e.err
+	}
+
+	defer func() {
+		e.close = true
+		if err != nil {
+			e.err = err
+		}
+	}()
+
+	if !e.widthSet {
+		if err := e.flushBuffered(); err != nil {
+			return err
+		}
+	}
+
+	// the data validation is kept by the worksheet until the stream writer is flushed
+	if err := e.enc.title.writeDataValidation(); err != nil {
+		return fmt.Errorf("write data validation: %w", err)
+	}
+
+	if err := e.enc.title.writeRefValidation(refFields(reflect.TypeFor[T](), e.enc.typeOpts.structTag)); err != nil {
+		return fmt.Errorf("write ref data validation: %w", err)
+	}
+
+	if err := e.sw.Flush(); err != nil {
+		return fmt.Errorf("flush: %w", err)
+	}
+	return nil
+}
+
+// writeRow writes the row or buffers it until the width of columns is set.
+func (e *StreamEncoder[T]) writeRow(row streamRow) error {
+	if e.widthSet {
+		return e.setRow(row)
+	}
+
+	e.buffered = append(e.buffered, row)
+	if len(e.buffered) < streamWidthRows {
+		return nil
+	}
+	return e.flushBuffered()
+}
+
+// flushBuffered sets the width of columns by the buffered rows and writes them.
+func (e *StreamEncoder[T]) flushBuffered() error {
+	if err := e.setWidth(); err != nil {
+		return err
+	}
+	e.widthSet = true
+
+	for _, row := range e.buffered {
+		if err := e.setRow(row); err != nil {
+			return err
+		}
+	}
+	e.buffered = nil
+	return nil
+}
+
+// setWidth sets the width of columns measured by the values of the buffered data rows.
+func (e *StreamEncoder[T]) setWidth() error {
+	t := e.enc.title
+	if t.config.scaleAutoWidth == nil {
+		return nil
+	}
+
+	for _, n := range t.name {
+		maxWidth := t.config.maxWidth(n.Name)
+		if maxWidth == 0 {
+			continue
+		}
+
+		for _, c := range n.Column {
+			var width float64
+			for _, row := range e.buffered {
+				if row.row == t.config.rowIndex || c > len(row.values) || row.values[c-1] == nil {
+					continue
+				}
+
+				value := row.values[c-1]
+				if v, ok := value.(time.Time); ok {
+					value = v.Format(defaultTimeFormat)
+				}
+
+				cellWidth := t.config.scaleAutoWidth(utf8.RuneCountInString(fmt.Sprint(value)))
+				if maxWidth != -1 && cellWidth > maxWidth {
+					cellWidth = maxWidth
+				}
+				width = max(width, cellWidth)
+			}
+
+			if width == 0 {
+				continue
+			}
+
+			if err := e.sw.SetColWidth(c, c, width); err != nil {
+				return fmt.Errorf("excelstruct: title %q column %d width: %w", n.Name, c, err)
+			}
+		}
+	}
+	return nil
+}
+
+// setRow writes the row by the stream writer with the style of columns.
+func (e *StreamEncoder[T]) setRow(row streamRow) error {
+	cells := make([]any, max(len(e.styles), len(row.values)))
+	for i := range cells {
+		var c excelize.Cell
+		if i < len(e.styles) {
+			c.StyleID = e.styles[i]
+		}
+
+		if i < len(row.values) {
+			c.Value = row.values[i]
+		}
+		cells[i] = c
+	}
+
+	cell, err := excelize.CoordinatesToCellName(1, row.row)
+	if err != nil {
+		return fmt.Errorf("excelstruct: row %d: %w", row.row, err)
+	}
+
+	if err := e.sw.SetRow(cell, cells); err != nil {
+		return fmt.Errorf("excelstruct: row %d: %w", row.row, err)
+	}
+	return nil
+}
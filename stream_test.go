@@ -0,0 +1,95 @@
+package excelstruct
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestStreamEncoder(t *testing.T) {
+	t.Parallel()
+
+	type v struct {
+		Name string    `excel:"name"`
+		Date time.Time `excel:"date"`
+		Qty  int       `excel:"qty"`
+	}
+
+	t.Run("write", func(t *testing.T) {
+		t.Parallel()
+
+		f, err := WriteFile(WriteFileOptions{})
+		require.NoError(t, err)
+		defer f.Close()
+
+		sheet, err := NewStreamEncoder[v](f, EncoderOptions{
+			TitleScaleAutoWidth: DefaultScaleAutoWidth,
+			TitleMaxWidth:       func(title string) float64 { return -1 },
+			DataValidation: func(title string) (*excelize.DataValidation, error) {
+				if title != "qty" {
+					return nil, nil
+				}
+
+				dv := excelize.NewDataValidation(true)
+				require.NoError(t, dv.SetRange(0, 1000, excelize.DataValidationTypeWhole, excelize.DataValidationOperatorBetween))
+				return dv, nil
+			},
+		})
+		require.NoError(t, err)
+
+		date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		want := make([]v, 0, streamWidthRows+50)
+		for i := range cap(want) {
+			want = append(want, v{Name: fmt.Sprintf("name %d", i), Date: date, Qty: i})
+		}
+		require.NoError(t, sheet.All(want))
+		require.NoError(t, sheet.Close())
+
+		b, err := f.File.WriteToBuffer()
+		require.NoError(t, err)
+
+		r, err := OpenBytes(b.Bytes(), OpenFileOptions{})
+		require.NoError(t, err)
+		defer r.Close()
+
+		dec, err := NewDecoder[v](r, DecoderOptions{})
+		require.NoError(t, err)
+		defer dec.Close()
+
+		var got []v
+		require.NoError(t, dec.All(&got))
+		assert.Equal(t, want, got)
+
+		dv, err := r.GetDataValidations(DefaultSheetName)
+		require.NoError(t, err)
+		require.Len(t, dv, 1)
+		assert.Equal(t, fmt.Sprintf("C2:C%d", len(want)+1), dv[0].Sqref)
+
+		width, err := r.GetColWidth(DefaultSheetName, "A")
+		require.NoError(t, err)
+		assert.Equal(t, DefaultScaleAutoWidth(len("name 99")), width)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		t.Parallel()
+
+		f, err := WriteFile(WriteFileOptions{})
+		require.NoError(t, err)
+		defer f.Close()
+
+		type s struct {
+			V []int `excel:"v"`
+		}
+
+		sheet, err := NewStreamEncoder[s](f, EncoderOptions{})
+		require.NoError(t, err)
+		require.ErrorIs(t, sheet.Encode(&s{V: []int{1, 2}}), ErrStreamUnsupported)
+
+		_, err = NewStreamEncoder[s](f, EncoderOptions{SheetName: "column", Orientation: OrientationColumn})
+		require.ErrorIs(t, err, ErrStreamUnsupported)
+	})
+}
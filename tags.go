@@ -11,6 +11,25 @@ const (
 
 	optOmitempty = "omitempty"
 	optInline    = "inline"
+	optConv      = "conv"
+	optSheet     = "sheet"
+	optRow       = "row"
+	optCells     = "cells"
+	optChildren  = "children"
+	optSection   = "section"
+	optComment   = "comment"
+	optHyperlink = "hyperlink"
+	optFill      = "fill"
+	optFont      = "font"
+	optFormula   = "formula"
+	optCalc      = "calc"
+	optCached    = "cached"
+	optGroup     = "group"
+	optRef       = "ref"
+	optKey       = "key"
+
+	convSeparator = "|"
+	groupNumber   = "{n}"
 )
 
 // tagOptions is the string following a comma in a struct field's "excel"
@@ -40,6 +59,62 @@ func (o tagOptions) Contains(optionName string) bool {
 	return false
 }
 
+// Get returns the value of a key=value option and reports whether the option exists.
+func (o tagOptions) Get(optionName string) (string, bool) {
+	s := string(o)
+	for s != "" {
+		var opt string
+		opt, s, _ = strings.Cut(s, ",")
+		if name, value, ok := strings.Cut(opt, "="); ok && name == optionName {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// parseMeta returns the option of the row metadata.
+func parseMeta(opts tagOptions) string {
+	for _, v := range []string{optSheet, optRow, optCells, optChildren, optSection} {
+		if opts.Contains(v) {
+			return v
+		}
+	}
+	return ""
+}
+
+// parseAttr returns the option of the cell attribute.
+func parseAttr(opts tagOptions) string {
+	for _, v := range []string{optComment, optHyperlink, optFill, optFont, optFormula} {
+		if opts.Contains(v) {
+			return v
+		}
+	}
+	return ""
+}
+
+// parseFormula returns the value of formula cells selected by the tag option.
+func parseFormula(opts tagOptions) Formula {
+	switch {
+	case opts.Contains(optCalc):
+		return FormulaCalc
+	case opts.Contains(optCached):
+		return FormulaCached
+	}
+	return ""
+}
+
+// parseGroup returns the title pattern of the repeated column groups.
+func parseGroup(opts tagOptions) string {
+	pattern, _ := opts.Get(optGroup)
+	return pattern
+}
+
+// parseRef returns the reference "Sheet.title" of the key column in another sheet.
+func parseRef(opts tagOptions) string {
+	ref, _ := opts.Get(optRef)
+	return ref
+}
+
 func isValidTag(s string) bool {
 	if s == "" {
 		return false
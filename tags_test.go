@@ -21,3 +21,14 @@ func TestParseTag(t *testing.T) {
 		assert.Equal(t, tt.want, opts.Contains(tt.opt))
 	}
 }
+
+func TestTagOptions_Get(t *testing.T) {
+	_, opts := parseTag("field,omitempty,conv=trim|upper")
+
+	v, ok := opts.Get("conv")
+	assert.True(t, ok)
+	assert.Equal(t, "trim|upper", v)
+
+	_, ok = opts.Get("omitempty")
+	assert.False(t, ok)
+}
@@ -29,6 +29,9 @@ type titleConfig struct {
 	numFmt            map[excelize.CellType]int
 	titleNumFmt       map[string]int
 	titleStyle        map[string]string
+	skipColumn        func(col int) bool
+	stream            bool // the cells are written by the stream writer, the columns can not be inserted
+	appendRow         int  // first row appended to the existing sheet, 0 if the title is written by the encoder
 }
 
 // A title is the title of Excel.
@@ -50,7 +53,7 @@ type titleName struct {
 }
 
 // newTitleFromFile returns the title after reading the Excel.
-func newTitleFromFile(config titleConfig, rows *excelize.Rows) (*title, error) {
+func newTitleFromFile(config titleConfig, rows rowIterator) (*title, error) {
 	i := 1
 	// <= because Next() have to put the pointer to the index row
 	for ; i <= config.rowIndex; i++ {
@@ -71,6 +74,10 @@ func newTitleFromFile(config titleConfig, rows *excelize.Rows) (*title, error) {
 	}
 
 	for i, name := range column {
+		if config.skipColumn != nil && config.skipColumn(i+1) {
+			continue
+		}
+
 		name = title.config.conv(name)
 		idx, ok := title.idx[name]
 		if ok {
@@ -97,7 +104,25 @@ func newTitleFromStruct[T any](config titleConfig, file *excelize.File) (*title,
 	v := reflect.ValueOf(new(T)).Elem()
 	switch v.Kind() {
 	case reflect.Struct:
-		ff := cachedTypeFields(v.Type(), typeOpts{structTag: config.tag}).list
+		var ff []field
+		for _, f := range cachedTypeFields(v.Type(), typeOpts{structTag: config.tag}).list {
+			switch {
+			case f.isValue():
+				ff = append(ff, f)
+
+			case f.group != "":
+				// the first group is written, the next groups are inserted on encode
+				st, ok := groupStruct(f.typ)
+				if !ok {
+					return nil, fmt.Errorf("field %q group requires slice of structs", f.name)
+				}
+
+				for _, sf := range groupFields(st, config.tag) {
+					sf.name = groupTitle(f.group, 1, sf.name)
+					ff = append(ff, sf)
+				}
+			}
+		}
 		inputName = make([]string, 0, len(ff))
 		nameIndex := make(map[string]int, len(ff))
 		for i, v := range ff {
@@ -174,6 +199,14 @@ func newTitleFromStruct[T any](config titleConfig, file *excelize.File) (*title,
 	}, nil
 }
 
+// firstDataRow returns the first row of the data written by the encoder.
+func (t *title) firstDataRow() int {
+	if t.config.appendRow > 0 {
+		return t.config.appendRow
+	}
+	return t.config.rowIndex + 1
+}
+
 // sqrefByRow returns the first range data by the row.
 func (t *title) sqrefByRow(name string) (string, error) {
 	idx, ok := t.idx[name]
@@ -249,6 +282,15 @@ func (t *title) rowData(name string) (int, bool) {
 	return 0, false
 }
 
+// setRowData sets the last row of the data of all titles.
+func (t *title) setRowData(row int) {
+	for _, n := range t.name {
+		for i := range n.Column {
+			n.RowData[i] = row
+		}
+	}
+}
+
 // incRowData increments the row data of the title.
 func (t *title) incRowData(name string, col int) {
 	if idx, ok := t.idx[name]; ok {
@@ -303,6 +345,10 @@ func (t *title) setWidth(name string, col int, cell string) error {
 
 // insertCols inserts the columns.
 func (t *title) insertCols(idx, count int) error {
+	if t.config.stream {
+		return fmt.Errorf("insert columns: %w", ErrStreamUnsupported)
+	}
+
 	col, _ := excelize.ColumnNumberToName(idx)
 	if err := t.file.InsertCols(t.config.sheetName, col, count); err != nil {
 		return err
@@ -348,7 +394,7 @@ func (t *title) writeDataValidation() error {
 	}
 
 	// no needs a data validation because there is no written data
-	if maxRow == t.config.rowIndex {
+	if maxRow < t.firstDataRow() {
 		return nil
 	}
 
@@ -363,7 +409,7 @@ func (t *title) writeDataValidation() error {
 		}
 
 		startCol, endCol := n.Column[0], n.Column[len(n.Column)-1]
-		from, _ := excelize.CoordinatesToCellName(startCol, t.config.rowIndex+1) // row: +1 for header
+		from, _ := excelize.CoordinatesToCellName(startCol, t.firstDataRow())
 		to, _ := excelize.CoordinatesToCellName(endCol, maxRow)
 		cell := from + ":" + to
 		dv.Sqref = cell
@@ -388,6 +434,18 @@ func (t *title) writeWidth() error {
 
 		for idx, c := range n.Column {
 			col, _ := excelize.ColumnNumberToName(c)
+			if t.config.appendRow > 0 {
+				// the column of the existing sheet is not narrowed
+				width, err := t.file.GetColWidth(t.config.sheetName, col)
+				if err != nil {
+					return fmt.Errorf("title %q column %q get width: %w", n.Name, col, err)
+				}
+
+				if width >= n.Width[idx] {
+					continue
+				}
+			}
+
 			if err := t.file.SetColWidth(t.config.sheetName, col, col, n.Width[idx]); err != nil {
 				return fmt.Errorf("title %q column %q width: %w", n.Name, col, err)
 			}
@@ -0,0 +1,230 @@
+package excelstruct
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// upsertState is the rows of the existing sheet by the key, the values with a new key are appended.
+type upsertState struct {
+	fields   []field
+	cols     []int          // column of the key fields
+	rows     map[string]int // row by the key
+	next     int            // row of the next appended value
+	inserted int
+	updated  int
+}
+
+// newUpsertState reads the keys of the existing rows, the rows with the empty key are not matched.
+// The key is compared by the raw value of the cell with the encoded value, the numbers are compared by value.
+func newUpsertState(t *title, fields []field) (*upsertState, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("upsert requires the field with the tag option %q", optKey)
+	}
+
+	cols := make([]int, 0, len(fields))
+	for _, f := range fields {
+		col, ok := t.columnIndex(f.name)
+		if !ok {
+			return nil, fmt.Errorf("key title %q not found", f.name)
+		}
+		cols = append(cols, col[0])
+	}
+
+	rows, err := t.file.Rows(t.config.sheetName)
+	if err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+	defer rows.Close()
+
+	u := &upsertState{fields: fields, cols: cols, rows: make(map[string]int), next: t.config.appendRow}
+	for row := 1; rows.Next() && row < t.config.appendRow; row++ {
+		if row <= t.config.rowIndex {
+			continue
+		}
+
+		column, err := rows.Columns(excelize.Options{RawCellValue: true})
+		if err != nil {
+			return nil, fmt.Errorf("row %d columns: %w", row, err)
+		}
+
+		values := make([]string, 0, len(cols))
+		keys := make([]string, 0, len(cols))
+		empty := true
+		for i, c := range cols {
+			var v string
+			if c <= len(column) {
+				v = strings.TrimSpace(column[c-1])
+			}
+			empty = empty && isEmptyString(v)
+			values = append(values, v)
+			keys = append(keys, keyText(fields[i], v))
+		}
+
+		if empty {
+			continue
+		}
+
+		key := strings.Join(keys, keySeparator)
+		if first, ok := u.rows[key]; ok {
+			return nil, &DuplicateKeyError{
+				Key:        values,
+				Sheet:      t.config.sheetName,
+				Row:        row,
+				FirstSheet: t.config.sheetName,
+				FirstRow:   first,
+			}
+		}
+		u.rows[key] = row
+	}
+
+	if err := rows.Error(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+	return u, nil
+}
+
+// encodeUpsert writes v to the row with the same key or appends it after the last row.
+func (e *Encoder[T]) encodeUpsert(v *T) error {
+	u := e.upsert
+	var rv reflect.Value
+	if v != nil {
+		rv = reflect.ValueOf(v).Elem()
+	}
+
+	// the updated rows are not counted as written after the last row
+	defer func() { e.enc.title.setRowData(u.next - 1) }()
+
+	key, empty, err := e.encodeKey(rv)
+	if err != nil {
+		return err
+	}
+
+	// the value with the empty key is always appended
+	row, ok := u.rows[key]
+	ok = ok && !empty
+	if ok {
+		if err := e.clearRow(row); err != nil {
+			return err
+		}
+	} else {
+		row = u.next
+	}
+
+	e.enc.row = row
+	if err := e.enc.marshal(v); err != nil {
+		return err
+	}
+
+	if ok {
+		u.updated++
+	} else {
+		u.next++
+		if !empty {
+			u.rows[key] = row
+		}
+		u.inserted++
+	}
+	return nil
+}
+
+// encodeKey returns the key of the values which are written to the cells of the key fields
+// and whether all of them are empty.
+func (e *Encoder[T]) encodeKey(v reflect.Value) (key string, empty bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if je, ok := r.(excelError); ok {
+				err = je.error
+			} else {
+				panic(r)
+			}
+		}
+	}()
+
+	u := e.upsert
+	enc := *e.enc
+	enc.stream = &streamRow{row: enc.row}
+	for _, f := range u.fields {
+		fv := fieldValue(v, f.index)
+		if !fv.IsValid() || !enc.setField(f.name) {
+			continue
+		}
+
+		opts := enc.encOpts
+		opts.conv = f.conv
+		f.encoder(&enc, fv, opts)
+	}
+
+	keys := make([]string, 0, len(u.fields))
+	empty = true
+	for i, f := range u.fields {
+		var value any
+		if c := u.cols[i]; c <= len(enc.stream.values) {
+			value = enc.stream.values[c-1]
+		}
+
+		var text string
+		switch value := value.(type) {
+		case nil:
+		case bool:
+			// the raw value of the boolean cell
+			text = "0"
+			if value {
+				text = "1"
+			}
+		default:
+			text = strings.TrimSpace(fmt.Sprint(value))
+		}
+		empty = empty && isEmptyString(text)
+		keys = append(keys, keyText(f, text))
+	}
+	return strings.Join(keys, keySeparator), empty, nil
+}
+
+// keyText returns the text of the key value, the numbers of the number field are compared by value.
+func keyText(f field, text string) string {
+	t := f.typ
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return strconv.FormatInt(n, 10)
+		}
+
+		if n, err := strconv.ParseFloat(text, 64); err == nil {
+			return strconv.FormatFloat(n, 'g', -1, 64)
+		}
+	}
+	return text
+}
+
+// clearRow clears the values of the title columns in the row, the styles are kept.
+func (e *Encoder[T]) clearRow(row int) error {
+	t := e.enc.title
+	for _, n := range t.name {
+		for _, c := range n.Column {
+			cell, _ := excelize.CoordinatesToCellName(c, row)
+			if err := e.SetCellValue(t.config.sheetName, cell, nil); err != nil {
+				return fmt.Errorf("excelstruct: cell %q clear: %w", cell, err)
+			}
+		}
+	}
+	return nil
+}
+
+// UpsertCount returns the number of the inserted and the updated rows in the upsert mode.
+func (e *Encoder[T]) UpsertCount() (inserted, updated int) {
+	if e.upsert == nil {
+		return 0, 0
+	}
+	return e.upsert.inserted, e.upsert.updated
+}